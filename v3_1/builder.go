@@ -2,7 +2,9 @@ package oas
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -23,6 +25,8 @@ type PathFactory func(string) string
 type Builder struct {
 	doc         *Document
 	pathFactory PathFactory
+	schemas     *schemaRegistry
+	errs        []error // tags malformadas vistas por SchemaOf
 }
 
 func NewBuilder() *Builder {
@@ -96,6 +100,38 @@ func (b *Builder) AddSchema(name string, schema Schema) *Builder {
 	return b
 }
 
+// WithSchemaNamer troca o nome dos structs registrados por SchemaOf
+// (padrão: DefaultSchemaNamer).
+func (b *Builder) WithSchemaNamer(namer SchemaNamer) *Builder {
+	b.registry().namer = namer
+	return b
+}
+
+// SchemaOf gera o schema de t como oas.SchemaOf, mas registra cada struct
+// nomeado uma única vez em components/schemas e devolve $ref para ele (em
+// todos os lugares, inclusive em tipos recursivos). Tags malformadas não
+// entram em pânico: o campo fica sem a tag e o erro sai em Err, JSON e YAML.
+func (b *Builder) SchemaOf(t reflect.Type) SchemaOrRef {
+	r := newReflector(t)
+	r.registry = b.registry()
+	s := r.schema(t)
+	b.errs = append(b.errs, r.errs...)
+	return s
+}
+
+// Err devolve os erros das tags dos tipos passados a SchemaOf (e a
+// RequestJSONOf/ResponseJSONOf), ou nil.
+func (b *Builder) Err() error {
+	return errors.Join(b.errs...)
+}
+
+func (b *Builder) registry() *schemaRegistry {
+	if b.schemas == nil {
+		b.schemas = newSchemaRegistry(b.doc.Components)
+	}
+	return b.schemas
+}
+
 func (b *Builder) AddSecurityScheme(name string, scheme SecurityScheme) *Builder {
 	if b.doc.Components.SecuritySchemes == nil {
 		b.doc.Components.SecuritySchemes = make(map[string]SecuritySchemeOrRef)
@@ -119,6 +155,18 @@ func (b *Builder) ExternalDocs(desc, url string) *Builder {
 	return b
 }
 
+// SetExtension define uma extensão "x-*" no documento (ex.: x-tagGroups).
+func (b *Builder) SetExtension(key string, value any) *Builder {
+	b.doc.Extensions = b.doc.Extensions.With(key, value)
+	return b
+}
+
+// SetInfoExtension define uma extensão "x-*" em Info (ex.: x-logo).
+func (b *Builder) SetInfoExtension(key string, value any) *Builder {
+	b.doc.Info.Extensions = b.doc.Info.Extensions.With(key, value)
+	return b
+}
+
 func (b *Builder) Path(path string) *PathBuilder {
 	normalized := b.pathFactory(path)
 	piRef, ok := b.doc.Paths[normalized]
@@ -135,9 +183,19 @@ func (b *Builder) Build() *Document {
 }
 
 func (b *Builder) JSON() ([]byte, error) {
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
 	return json.MarshalIndent(b.doc, "", "  ")
 }
 
+func (b *Builder) YAML() ([]byte, error) {
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
+	return b.doc.YAML()
+}
+
 // =======================
 // Path Builder
 // =======================
@@ -152,6 +210,12 @@ func (pb *PathBuilder) DonePath() *Builder {
 	return pb.builder
 }
 
+// SetExtension define uma extensão "x-*" no path item.
+func (pb *PathBuilder) SetExtension(key string, value any) *PathBuilder {
+	pb.item.Extensions = pb.item.Extensions.With(key, value)
+	return pb
+}
+
 func (pb *PathBuilder) Get(summary string) *OperationBuilder {
 	return pb.addOp("get", summary)
 }
@@ -263,6 +327,12 @@ func (ob *OperationBuilder) SetDeprecated() *OperationBuilder {
 	return ob
 }
 
+// SetExtension define uma extensão "x-*" na operação (ex.: x-internal).
+func (ob *OperationBuilder) SetExtension(key string, value any) *OperationBuilder {
+	ob.op.Extensions = ob.op.Extensions.With(key, value)
+	return ob
+}
+
 // ---------------- Params -----------------
 
 func (ob *OperationBuilder) ParamQuery(name, typ, desc string, required bool) *OperationBuilder {
@@ -304,6 +374,12 @@ func (ob *OperationBuilder) RequestJSON(schema SchemaOrRef, required bool) *Oper
 	return ob
 }
 
+// RequestJSONOf é RequestJSON com o schema do tipo de v (ver Builder.SchemaOf):
+// ob.RequestJSONOf(CreateUser{}, true).
+func (ob *OperationBuilder) RequestJSONOf(v any, required bool) *OperationBuilder {
+	return ob.RequestJSON(ob.pathBuilder.builder.SchemaOf(reflect.TypeOf(v)), required)
+}
+
 // ---------------- Responses -----------------
 
 func (ob *OperationBuilder) ResponseStatus(status int, desc string) *OperationBuilder {
@@ -325,6 +401,12 @@ func (ob *OperationBuilder) ResponseJSON(status int, desc string, schema SchemaO
 	return ob
 }
 
+// ResponseJSONOf é ResponseJSON com o schema do tipo de v (ver
+// Builder.SchemaOf): ob.ResponseJSONOf(200, "ok", []User{}).
+func (ob *OperationBuilder) ResponseJSONOf(status int, desc string, v any) *OperationBuilder {
+	return ob.ResponseJSON(status, desc, ob.pathBuilder.builder.SchemaOf(reflect.TypeOf(v)))
+}
+
 func (ob *OperationBuilder) ResponseText(status int, desc string) *OperationBuilder {
 	resp := Response{
 		Description: desc,
@@ -0,0 +1,200 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ========== Bundle ==========
+
+// Bundle devolve uma cópia autocontida do documento: cada alvo de $ref
+// externo vira uma entrada no mapa de Components do tipo correspondente e o
+// $ref passa a apontar para "#/components/...". Definições idênticas são
+// reaproveitadas e nomes repetidos recebem sufixo numérico (User, User2, ...).
+// As opções são as do Resolver (WithBaseURI, WithRefLoader, ...).
+func Bundle(doc *Document, opts ...ResolverOption) (*Document, error) {
+	var out Document
+	if err := decodeNode(doc, &out); err != nil {
+		return nil, err
+	}
+	hadComponents := out.Components != nil
+	if !hadComponents {
+		out.Components = &Components{}
+	}
+	r := NewResolver(&out, opts...)
+	if r.err != nil {
+		return nil, r.err
+	}
+	out.sourceMap = doc.sourceMap
+	b := &bundler{r: r, components: out.Components, local: map[string]string{}, sm: doc.sourceMap}
+	b.walker = &refWalker{visit: b.visit}
+	if err := b.walker.document(&out); err != nil {
+		return nil, err
+	}
+	if !hadComponents && reflect.ValueOf(*out.Components).IsZero() {
+		out.Components = nil
+	}
+	return &out, nil
+}
+
+type bundler struct {
+	r          *Resolver
+	components *Components
+	walker     *refWalker
+	local      map[string]string // alvo externo canônico -> $ref local
+	sm         SourceMap
+}
+
+func (b *bundler) visit(ptr string, site refSite) error {
+	ref := site.reference()
+	if ref == nil {
+		return nil
+	}
+	target, fragment, err := b.r.target(ref.Ref)
+	if err != nil {
+		return b.refError(ptr, ref, err)
+	}
+	if sameDocument(target, b.r.base) {
+		// "openapi.yaml#/x" vira "#/x"
+		_, raw, _ := strings.Cut(ref.Ref, "#")
+		ref.Ref = "#" + raw
+		return nil
+	}
+	if _, ok := b.r.embedded(target); ok {
+		return nil // $id de um schema do próprio documento
+	}
+	key := target.String() + "#" + fragment
+	if local, ok := b.local[key]; ok {
+		ref.Ref = local
+		return nil
+	}
+	node, _, err := b.r.lookup(ref.Ref)
+	if err != nil {
+		return b.refError(ptr, ref, err)
+	}
+	kind := site.component()
+
+	// o próprio slot em Components aponta para fora: o alvo é embutido ali
+	if name, ok := componentSlot(ptr, kind); ok {
+		b.local[key] = componentRef(kind, name)
+		if err := replaceSite(site, node); err != nil {
+			return b.refError(ptr, ref, err)
+		}
+		return b.visit(ptr, site)
+	}
+
+	value := newSite(site)
+	if err := decodeNode(node, value); err != nil {
+		return b.refError(ptr, ref, err)
+	}
+	// reserva o nome antes de descer, para que $ref recursivos já apontem
+	// para ele, e só então compara: os $ref internos precisam estar na mesma
+	// forma (locais) das entradas que já estão em Components
+	entries := componentMap(b.components, kind)
+	name := freeName(entries, bundleBaseName(target, fragment))
+	b.local[key] = componentRef(kind, name)
+	entries.SetMapIndex(reflect.ValueOf(name), reflect.ValueOf(value).Elem())
+	if err := b.walker.site(ptrJoin("/components", kind, name), value); err != nil {
+		return err
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if same, ok := identicalEntry(entries, canonical, name); ok {
+		entries.SetMapIndex(reflect.ValueOf(name), reflect.Value{})
+		b.local[key] = componentRef(kind, same)
+	} else {
+		entries.SetMapIndex(reflect.ValueOf(name), reflect.ValueOf(value).Elem())
+	}
+	ref.Ref = b.local[key]
+	return nil
+}
+
+func (b *bundler) refError(ptr string, ref *Reference, err error) *RefError {
+	return &RefError{Ref: ref.Ref, Err: err, Pointer: ptr, Position: b.sm.at(ptr)}
+}
+
+func componentRef(kind, name string) string {
+	return "#/components/" + kind + "/" + escapePointerToken(name)
+}
+
+// componentSlot informa se ptr é exatamente "/components/<kind>/<nome>".
+func componentSlot(ptr, kind string) (string, bool) {
+	rest, ok := strings.CutPrefix(ptr, "/components/"+kind+"/")
+	if !ok || strings.Contains(rest, "/") {
+		return "", false
+	}
+	return unescapePointerToken(rest), true
+}
+
+// componentMap devolve (criando se preciso) o mapa de Components pelo nome JSON.
+func componentMap(c *Components, kind string) reflect.Value {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name == kind {
+			m := v.Field(i)
+			if m.IsNil() {
+				m.Set(reflect.MakeMap(m.Type()))
+			}
+			return m
+		}
+	}
+	panic("oas: componente desconhecido " + kind)
+}
+
+// freeName devolve base ou, se já usado, base2, base3... até achar um nome livre.
+func freeName(entries reflect.Value, base string) string {
+	name := base
+	for i := 2; entries.MapIndex(reflect.ValueOf(name)).IsValid(); i++ {
+		name = base + strconv.Itoa(i)
+	}
+	return name
+}
+
+// identicalEntry procura, fora skip, uma entrada com o mesmo JSON de canonical.
+func identicalEntry(entries reflect.Value, canonical []byte, skip string) (string, bool) {
+	keys := make([]string, 0, entries.Len())
+	for _, k := range entries.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == skip {
+			continue
+		}
+		existing, err := json.Marshal(entries.MapIndex(reflect.ValueOf(k)).Interface())
+		if err == nil && bytes.Equal(existing, canonical) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+var reComponentName = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// bundleBaseName deriva o nome do último token do fragmento ou, sem ele, do
+// nome do arquivo sem extensão.
+func bundleBaseName(target *url.URL, fragment string) string {
+	name := ""
+	if tokens, err := splitPointer(fragment); err == nil && len(tokens) > 0 {
+		name = tokens[len(tokens)-1]
+	}
+	if name == "" {
+		file := path.Base(target.Path)
+		name = strings.TrimSuffix(file, path.Ext(file))
+	}
+	name = strings.Trim(reComponentName.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		name = "component"
+	}
+	return name
+}
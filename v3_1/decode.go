@@ -0,0 +1,296 @@
+package oas
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ========== Decodificação com localização de erros ==========
+
+// unmarshalDocument decodifica o JSON de um documento e anexa o source map
+// (se houver). Se algum valor não couber no tipo esperado, o erro vira um
+// *ValidationError (CodeDecode) com o JSON Pointer do nó mais profundo que
+// falhou. Em modo estrito, campos desconhecidos voltam como ValidationErrors.
+func unmarshalDocument(raw []byte, sm SourceMap, strict bool) (*Document, error) {
+	var doc Document
+	if err := unmarshalValue(raw, &doc, sm, strict); err != nil {
+		return nil, err
+	}
+	doc.sourceMap = sm
+	return &doc, nil
+}
+
+// unmarshalValue é unmarshalDocument para qualquer valor do pacote.
+func unmarshalValue(raw []byte, v any, sm SourceMap, strict bool) error {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Pointer || reflect.ValueOf(v).IsNil() {
+		return &json.InvalidUnmarshalError{Type: t}
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		err = locateDecodeError(raw, t, err)
+		if ve, ok := err.(*ValidationError); ok {
+			ve.Position = sm.nearest(ve.Pointer)
+		}
+		return err
+	}
+	if strict {
+		return checkUnknownFields(raw, t, sm)
+	}
+	return nil
+}
+
+// checkUnknownFields aponta as chaves de raw sem campo correspondente em t.
+func checkUnknownFields(raw []byte, t reflect.Type, sm SourceMap) error {
+	var node any
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return err
+	}
+	var errs ValidationErrors
+	for _, ptr := range unknownFields(node, t, "") {
+		errs = append(errs, &ValidationError{
+			Pointer:  ptr,
+			Code:     CodeUnknownField,
+			Severity: SeverityError,
+			Message:  "campo desconhecido",
+			Position: sm.nearest(ptr),
+		})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+var (
+	schemaType      = reflect.TypeOf(Schema{})
+	schemaOrRefType = reflect.TypeOf(SchemaOrRef{})
+)
+
+// itemMaps são os objetos decodificados como mapa que aceitam chaves "x-*"
+// ao lado dos itens.
+var itemMaps = map[reflect.Type]bool{
+	reflect.TypeOf(Paths{}):     true,
+	reflect.TypeOf(Responses{}): true,
+	reflect.TypeOf(Callback{}):  true,
+}
+
+// unknownFields lista, em ordem, os ponteiros das chaves de node que não
+// existem no tipo t. Extensões "x-*" são sempre aceitas.
+func unknownFields(node any, t reflect.Type, ptr string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isUnion(t) {
+		// $ref de schema pode ter keywords ao lado
+		if obj, ok := node.(map[string]any); ok && t == schemaOrRefType {
+			if _, isRef := obj["$ref"]; isRef {
+				siblings := make(map[string]any, len(obj))
+				for k, v := range obj {
+					if k != "$ref" {
+						siblings[k] = v
+					}
+				}
+				return unknownFields(siblings, schemaType, ptr)
+			}
+		}
+		alt, ok := unionVariant(t, node)
+		if !ok && isObject(node) && hasField(t, schemaOrRefType) {
+			// Items, AdditionalProperties: {"$ref": ...} é um SchemaOrRef
+			alt, ok = schemaOrRefType, true
+		}
+		if ok {
+			return unknownFields(node, alt, ptr)
+		}
+		return nil
+	}
+	var out []string
+	switch n := node.(type) {
+	case map[string]any:
+		if t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+			return nil
+		}
+		for _, k := range sortedKeys(n) {
+			if (t.Kind() == reflect.Struct || itemMaps[t]) && isExtension(k) {
+				continue
+			}
+			child, ok := memberType(t, k)
+			if !ok {
+				out = append(out, ptrJoin(ptr, k))
+				continue
+			}
+			out = append(out, unknownFields(n[k], child, ptrJoin(ptr, k))...)
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range n {
+				out = append(out, unknownFields(item, t.Elem(), ptrJoin(ptr, strconv.Itoa(i)))...)
+			}
+		}
+	}
+	return out
+}
+
+// locateDecodeError refaz a decodificação nó a nó para achar onde err ocorreu.
+// Erros de sintaxe são devolvidos sem alteração.
+func locateDecodeError(raw []byte, t reflect.Type, err error) error {
+	var node any
+	if json.Unmarshal(raw, &node) != nil {
+		return err
+	}
+	ptr, cause := locateDecode(node, t, "")
+	if cause == nil {
+		cause = err
+	}
+	return &ValidationError{
+		Pointer:  ptr,
+		Code:     CodeDecode,
+		Severity: SeverityError,
+		Message:  cause.Error(),
+		Err:      cause,
+	}
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// locateDecode devolve o ponteiro e o erro do nó mais profundo que não
+// decodifica em t ("" e nil se node decodifica sem erro).
+func locateDecode(node any, t reflect.Type, ptr string) (string, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	data, err := json.Marshal(node)
+	if err != nil {
+		return ptr, err
+	}
+	cause := json.Unmarshal(data, reflect.New(t).Interface())
+	if cause == nil {
+		return "", nil
+	}
+	// tipos união (SchemaOrRef, StringOrArray, ...) delegam a um dos campos
+	if isUnion(t) {
+		if alt, ok := unionVariant(t, node); ok {
+			if p, err := locateDecode(node, alt, ptr); err != nil {
+				return p, err
+			}
+		}
+		return ptr, cause
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(n) {
+			child, ok := memberType(t, k)
+			if !ok {
+				continue
+			}
+			if p, err := locateDecode(n[k], child, ptrJoin(ptr, k)); err != nil {
+				return p, err
+			}
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range n {
+				if p, err := locateDecode(item, t.Elem(), ptrJoin(ptr, strconv.Itoa(i))); err != nil {
+					return p, err
+				}
+			}
+		}
+	}
+	return ptr, cause
+}
+
+// isUnion reconhece as structs com UnmarshalJSON próprio e campos sem tag
+// json: cada campo é uma das formas aceitas do valor.
+func isUnion(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || !reflect.PointerTo(t).Implements(unmarshalerType) {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, tagged := t.Field(i).Tag.Lookup("json"); tagged {
+			return false
+		}
+	}
+	return t.NumField() > 0
+}
+
+// unionVariant escolhe o campo da união compatível com o formato do nó.
+func unionVariant(t reflect.Type, node any) (reflect.Type, bool) {
+	obj, _ := node.(map[string]any)
+	_, isRef := obj["$ref"]
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i).Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		var ok bool
+		switch node.(type) {
+		case map[string]any:
+			if ft == reflect.TypeOf(Reference{}) {
+				ok = isRef
+			} else {
+				ok = !isRef && (ft.Kind() == reflect.Struct || ft.Kind() == reflect.Map)
+			}
+		case []any:
+			ok = ft.Kind() == reflect.Slice
+		case bool:
+			ok = ft.Kind() == reflect.Bool
+		case string:
+			ok = ft.Kind() == reflect.String
+		case float64:
+			ok = ft.Kind() >= reflect.Int && ft.Kind() <= reflect.Float64
+		}
+		if ok {
+			return ft, true
+		}
+	}
+	return nil, false
+}
+
+func isObject(node any) bool {
+	_, ok := node.(map[string]any)
+	return ok
+}
+
+// hasField informa se a struct t tem um campo do tipo ft (ou *ft).
+func hasField(t, ft reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i).Type
+		for f.Kind() == reflect.Pointer {
+			f = f.Elem()
+		}
+		if f == ft {
+			return true
+		}
+	}
+	return false
+}
+
+// memberType devolve o tipo esperado para a chave key de um objeto do tipo t.
+func memberType(t reflect.Type, key string) (reflect.Type, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem(), true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if f.Anonymous && name == "" {
+				if ft, ok := memberType(f.Type, key); ok {
+					return ft, true
+				}
+				continue
+			}
+			if name == "-" || !f.IsExported() {
+				continue
+			}
+			if name == key || (name == "" && strings.EqualFold(f.Name, key)) {
+				return f.Type, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,106 @@
+package oas
+
+// ========== Dereference ==========
+
+// DereferenceOptions controla Dereference.
+type DereferenceOptions struct {
+	// ResolverOptions configura os $ref externos (WithBaseURI, WithRefLoader, ...).
+	ResolverOptions []ResolverOption
+	// CircularDepth é quantas vezes um $ref circular ainda é expandido antes
+	// do corte. Zero corta na primeira repetição.
+	CircularDepth int
+	// DropCircular substitui, no corte, o $ref circular por um objeto vazio
+	// (para schemas, o schema que aceita qualquer valor). Sem ele o $ref é
+	// mantido apontando para components.
+	DropCircular bool
+}
+
+// CircularRef registra um $ref circular cortado durante Dereference.
+type CircularRef struct {
+	Ref     string // alvo, ex.: "#/components/schemas/Node"
+	Pointer string // onde o corte aconteceu no documento gerado
+}
+
+// Dereference devolve uma cópia do documento com todo $ref substituído pelo
+// valor apontado. $ref externos são antes embutidos via Bundle. Os $ref
+// circulares são tratados conforme opts e listados no retorno.
+func Dereference(doc *Document, opts DereferenceOptions) (*Document, []CircularRef, error) {
+	out, err := Bundle(doc, opts.ResolverOptions...)
+	if err != nil {
+		return nil, nil, err
+	}
+	d := &dereferencer{
+		r:      NewResolver(out),
+		sm:     out.sourceMap,
+		opts:   opts,
+		active: map[string]int{},
+	}
+	w := &refWalker{visit: d.visit, leave: d.leave}
+	if err := w.document(out); err != nil {
+		return nil, nil, err
+	}
+	return out, d.circular, nil
+}
+
+type dereferencer struct {
+	r        *Resolver
+	sm       SourceMap
+	opts     DereferenceOptions
+	active   map[string]int // $ref em expansão no caminho atual -> profundidade
+	frames   [][]string     // $ref empilhados por cada site visitado
+	circular []CircularRef
+}
+
+func (d *dereferencer) visit(ptr string, site refSite) error {
+	var pushed []string
+	push := func(key string) {
+		d.active[key]++
+		pushed = append(pushed, key)
+	}
+	defer func() { d.frames = append(d.frames, pushed) }()
+
+	// entrar numa definição de components equivale a seguir um $ref até ela
+	if name, ok := componentSlot(ptr, site.component()); ok {
+		push(componentRef(site.component(), name))
+	}
+	var refs []*Reference
+	defer func() { applyOverrides(site, refs) }()
+	for ref := site.reference(); ref != nil; ref = site.reference() {
+		if s, ok := site.(*SchemaOrRef); ok && s.Schema != nil {
+			// $ref com irmãos: {"$ref": X, ...} vira {..., "allOf": [{"$ref": X}]}
+			// e o alvo é expandido dentro de allOf
+			s.Schema.AllOf = append(AllOf{{Ref: ref}}, s.Schema.AllOf...)
+			s.Ref = nil
+			return nil
+		}
+		if d.active[ref.Ref] > d.opts.CircularDepth {
+			d.circular = append(d.circular, CircularRef{Ref: ref.Ref, Pointer: ptr})
+			if d.opts.DropCircular {
+				return replaceSite(site, map[string]any{})
+			}
+			return nil
+		}
+		node, _, err := d.r.lookup(ref.Ref)
+		if err != nil {
+			return d.refError(ptr, ref, err)
+		}
+		push(ref.Ref)
+		if err := replaceSite(site, node); err != nil {
+			return d.refError(ptr, ref, err)
+		}
+		refs = append(refs, ref)
+	}
+	return nil
+}
+
+func (d *dereferencer) refError(ptr string, ref *Reference, err error) *RefError {
+	return &RefError{Ref: ref.Ref, Err: err, Pointer: ptr, Position: d.sm.at(ptr)}
+}
+
+func (d *dereferencer) leave(string, refSite) {
+	last := len(d.frames) - 1
+	for _, key := range d.frames[last] {
+		d.active[key]--
+	}
+	d.frames = d.frames[:last]
+}
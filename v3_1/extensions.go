@@ -0,0 +1,461 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ========== Extensões de especificação ("x-*") ==========
+
+// Extensions guarda as extensões "x-*" de um objeto. No JSON/YAML elas ficam
+// no mesmo nível dos demais campos: {"summary": "...", "x-internal": true}.
+type Extensions map[string]any
+
+// With devolve e com key definida, criando o mapa se preciso:
+// tag.Extensions = tag.Extensions.With("x-displayName", "Usuários").
+func (e Extensions) With(key string, value any) Extensions {
+	if e == nil {
+		e = Extensions{}
+	}
+	e[key] = value
+	return e
+}
+
+func isExtension(key string) bool {
+	return strings.HasPrefix(key, "x-")
+}
+
+// marshalExtensible serializa v (sem MarshalJSON próprio) e acrescenta ext ao
+// final do objeto, em ordem alfabética.
+func marshalExtensible(v any, ext Extensions) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendExtensions(b, ext)
+}
+
+// appendExtensions acrescenta ext ao final do objeto JSON b (null conta como
+// objeto vazio), em ordem alfabética.
+func appendExtensions(b []byte, ext Extensions) ([]byte, error) {
+	if len(ext) == 0 {
+		return b, nil
+	}
+	for key := range ext {
+		if !isExtension(key) {
+			return nil, fmt.Errorf("extensão %q deve começar com x-", key)
+		}
+	}
+	extra, err := json.Marshal(map[string]any(ext))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == len("{}") || string(b) == "null" {
+		return extra, nil
+	}
+	return append(append(b[:len(b)-1], ','), extra[1:]...), nil
+}
+
+// extendMember acrescenta ext ao objeto do membro key do objeto JSON b,
+// criando o membro ao final de b se ele não existe.
+func extendMember(b []byte, key string, ext Extensions) ([]byte, error) {
+	if len(ext) == 0 {
+		return b, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		name, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		if name != key {
+			continue
+		}
+		end := int(dec.InputOffset())
+		member, err := appendExtensions(value, ext)
+		if err != nil {
+			return nil, err
+		}
+		return slices.Concat(b[:end-len(value)], member, b[end:]), nil
+	}
+	member, err := appendExtensions([]byte("{}"), ext)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := json.Marshal(key)
+	out := slices.Clip(b[:len(b)-1])
+	if len(b) > len("{}") {
+		out = append(out, ',')
+	}
+	return slices.Concat(out, name, []byte{':'}, member, []byte{'}'}), nil
+}
+
+// unmarshalExtensible decodifica b em v (sem UnmarshalJSON próprio) e separa
+// as chaves "x-*" em ext.
+func unmarshalExtensible(b []byte, v any, ext *Extensions) error {
+	if err := json.Unmarshal(b, v); err != nil {
+		return err
+	}
+	return extensionsOf(b, ext)
+}
+
+// memberExtensions separa em ext as chaves "x-*" do objeto no membro key do
+// objeto JSON b.
+func memberExtensions(b []byte, key string, ext *Extensions) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*ext = nil
+	if member, ok := raw[key]; ok {
+		return extensionsOf(member, ext)
+	}
+	return nil
+}
+
+// extensionsOf separa em ext as chaves "x-*" do objeto JSON b.
+func extensionsOf(b []byte, ext *Extensions) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*ext = nil
+	for key, value := range raw {
+		if !isExtension(key) {
+			continue
+		}
+		var x any
+		if err := json.Unmarshal(value, &x); err != nil {
+			return err
+		}
+		if *ext == nil {
+			*ext = Extensions{}
+		}
+		(*ext)[key] = x
+	}
+	return nil
+}
+
+// Cada tipo extensível delega a um tipo "plain" de mesma estrutura, sem os
+// métodos, para não recursar.
+
+func (d Document) MarshalJSON() ([]byte, error) {
+	type plain Document
+	b, err := json.Marshal(plain(d))
+	if err != nil {
+		return nil, err
+	}
+	if b, err = extendMember(b, "paths", d.PathsExtensions); err != nil {
+		return nil, err
+	}
+	return appendExtensions(b, d.Extensions)
+}
+
+func (d *Document) UnmarshalJSON(b []byte) error {
+	type plain Document
+	if err := unmarshalExtensible(b, (*plain)(d), &d.Extensions); err != nil {
+		return err
+	}
+	return memberExtensions(b, "paths", &d.PathsExtensions)
+}
+
+func (i Info) MarshalJSON() ([]byte, error) {
+	type plain Info
+	return marshalExtensible(plain(i), i.Extensions)
+}
+
+func (i *Info) UnmarshalJSON(b []byte) error {
+	type plain Info
+	return unmarshalExtensible(b, (*plain)(i), &i.Extensions)
+}
+
+func (c Contact) MarshalJSON() ([]byte, error) {
+	type plain Contact
+	return marshalExtensible(plain(c), c.Extensions)
+}
+
+func (c *Contact) UnmarshalJSON(b []byte) error {
+	type plain Contact
+	return unmarshalExtensible(b, (*plain)(c), &c.Extensions)
+}
+
+func (l License) MarshalJSON() ([]byte, error) {
+	type plain License
+	return marshalExtensible(plain(l), l.Extensions)
+}
+
+func (l *License) UnmarshalJSON(b []byte) error {
+	type plain License
+	return unmarshalExtensible(b, (*plain)(l), &l.Extensions)
+}
+
+func (s Server) MarshalJSON() ([]byte, error) {
+	type plain Server
+	return marshalExtensible(plain(s), s.Extensions)
+}
+
+func (s *Server) UnmarshalJSON(b []byte) error {
+	type plain Server
+	return unmarshalExtensible(b, (*plain)(s), &s.Extensions)
+}
+
+func (s ServerVariable) MarshalJSON() ([]byte, error) {
+	type plain ServerVariable
+	return marshalExtensible(plain(s), s.Extensions)
+}
+
+func (s *ServerVariable) UnmarshalJSON(b []byte) error {
+	type plain ServerVariable
+	return unmarshalExtensible(b, (*plain)(s), &s.Extensions)
+}
+
+func (p PathItem) MarshalJSON() ([]byte, error) {
+	type plain PathItem
+	return marshalExtensible(plain(p), p.Extensions)
+}
+
+func (p *PathItem) UnmarshalJSON(b []byte) error {
+	type plain PathItem
+	return unmarshalExtensible(b, (*plain)(p), &p.Extensions)
+}
+
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type plain Operation
+	b, err := json.Marshal(plain(o))
+	if err != nil {
+		return nil, err
+	}
+	if b, err = extendMember(b, "responses", o.ResponsesExtensions); err != nil {
+		return nil, err
+	}
+	return appendExtensions(b, o.Extensions)
+}
+
+func (o *Operation) UnmarshalJSON(b []byte) error {
+	type plain Operation
+	if err := unmarshalExtensible(b, (*plain)(o), &o.Extensions); err != nil {
+		return err
+	}
+	return memberExtensions(b, "responses", &o.ResponsesExtensions)
+}
+
+func (e ExternalDocumentation) MarshalJSON() ([]byte, error) {
+	type plain ExternalDocumentation
+	return marshalExtensible(plain(e), e.Extensions)
+}
+
+func (e *ExternalDocumentation) UnmarshalJSON(b []byte) error {
+	type plain ExternalDocumentation
+	return unmarshalExtensible(b, (*plain)(e), &e.Extensions)
+}
+
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	type plain Parameter
+	return marshalExtensible(plain(p), p.Extensions)
+}
+
+func (p *Parameter) UnmarshalJSON(b []byte) error {
+	type plain Parameter
+	return unmarshalExtensible(b, (*plain)(p), &p.Extensions)
+}
+
+func (r RequestBody) MarshalJSON() ([]byte, error) {
+	type plain RequestBody
+	return marshalExtensible(plain(r), r.Extensions)
+}
+
+func (r *RequestBody) UnmarshalJSON(b []byte) error {
+	type plain RequestBody
+	return unmarshalExtensible(b, (*plain)(r), &r.Extensions)
+}
+
+func (m MediaType) MarshalJSON() ([]byte, error) {
+	type plain MediaType
+	return marshalExtensible(plain(m), m.Extensions)
+}
+
+func (m *MediaType) UnmarshalJSON(b []byte) error {
+	type plain MediaType
+	return unmarshalExtensible(b, (*plain)(m), &m.Extensions)
+}
+
+func (e Encoding) MarshalJSON() ([]byte, error) {
+	type plain Encoding
+	return marshalExtensible(plain(e), e.Extensions)
+}
+
+func (e *Encoding) UnmarshalJSON(b []byte) error {
+	type plain Encoding
+	return unmarshalExtensible(b, (*plain)(e), &e.Extensions)
+}
+
+func (r Response) MarshalJSON() ([]byte, error) {
+	type plain Response
+	return marshalExtensible(plain(r), r.Extensions)
+}
+
+func (r *Response) UnmarshalJSON(b []byte) error {
+	type plain Response
+	return unmarshalExtensible(b, (*plain)(r), &r.Extensions)
+}
+
+func (h Header) MarshalJSON() ([]byte, error) {
+	type plain Header
+	return marshalExtensible(plain(h), h.Extensions)
+}
+
+func (h *Header) UnmarshalJSON(b []byte) error {
+	type plain Header
+	return unmarshalExtensible(b, (*plain)(h), &h.Extensions)
+}
+
+func (e Example) MarshalJSON() ([]byte, error) {
+	type plain Example
+	return marshalExtensible(plain(e), e.Extensions)
+}
+
+func (e *Example) UnmarshalJSON(b []byte) error {
+	type plain Example
+	return unmarshalExtensible(b, (*plain)(e), &e.Extensions)
+}
+
+func (l Link) MarshalJSON() ([]byte, error) {
+	type plain Link
+	return marshalExtensible(plain(l), l.Extensions)
+}
+
+func (l *Link) UnmarshalJSON(b []byte) error {
+	type plain Link
+	return unmarshalExtensible(b, (*plain)(l), &l.Extensions)
+}
+
+func (c Components) MarshalJSON() ([]byte, error) {
+	type plain Components
+	return marshalExtensible(plain(c), c.Extensions)
+}
+
+func (c *Components) UnmarshalJSON(b []byte) error {
+	type plain Components
+	return unmarshalExtensible(b, (*plain)(c), &c.Extensions)
+}
+
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	type plain SecurityScheme
+	return marshalExtensible(plain(s), s.Extensions)
+}
+
+func (s *SecurityScheme) UnmarshalJSON(b []byte) error {
+	type plain SecurityScheme
+	return unmarshalExtensible(b, (*plain)(s), &s.Extensions)
+}
+
+func (f OAuthFlows) MarshalJSON() ([]byte, error) {
+	type plain OAuthFlows
+	return marshalExtensible(plain(f), f.Extensions)
+}
+
+func (f *OAuthFlows) UnmarshalJSON(b []byte) error {
+	type plain OAuthFlows
+	return unmarshalExtensible(b, (*plain)(f), &f.Extensions)
+}
+
+func (f OAuthFlow) MarshalJSON() ([]byte, error) {
+	type plain OAuthFlow
+	return marshalExtensible(plain(f), f.Extensions)
+}
+
+func (f *OAuthFlow) UnmarshalJSON(b []byte) error {
+	type plain OAuthFlow
+	return unmarshalExtensible(b, (*plain)(f), &f.Extensions)
+}
+
+func (t Tag) MarshalJSON() ([]byte, error) {
+	type plain Tag
+	return marshalExtensible(plain(t), t.Extensions)
+}
+
+func (t *Tag) UnmarshalJSON(b []byte) error {
+	type plain Tag
+	return unmarshalExtensible(b, (*plain)(t), &t.Extensions)
+}
+
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	return marshalExtensible(plain(s), s.Extensions)
+}
+
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	type plain Schema
+	return unmarshalExtensible(b, (*plain)(s), &s.Extensions)
+}
+
+func (d Discriminator) MarshalJSON() ([]byte, error) {
+	type plain Discriminator
+	return marshalExtensible(plain(d), d.Extensions)
+}
+
+func (d *Discriminator) UnmarshalJSON(b []byte) error {
+	type plain Discriminator
+	return unmarshalExtensible(b, (*plain)(d), &d.Extensions)
+}
+
+func (x XML) MarshalJSON() ([]byte, error) {
+	type plain XML
+	return marshalExtensible(plain(x), x.Extensions)
+}
+
+func (x *XML) UnmarshalJSON(b []byte) error {
+	type plain XML
+	return unmarshalExtensible(b, (*plain)(x), &x.Extensions)
+}
+
+// Paths, Responses e Callback são mapas e não têm onde guardar extensões: as
+// chaves "x-*" deles ficam no dono (Document.PathsExtensions,
+// Operation.ResponsesExtensions e CallbackOrRef.Extensions) e são puladas
+// aqui, em vez de virarem itens.
+
+func (p *Paths) UnmarshalJSON(b []byte) error {
+	return unmarshalItems(b, (*map[string]PathItemOrRef)(p))
+}
+
+func (r *Responses) UnmarshalJSON(b []byte) error {
+	return unmarshalItems(b, (*map[string]ResponseOrRef)(r))
+}
+
+func (c *Callback) UnmarshalJSON(b []byte) error {
+	return unmarshalItems(b, (*map[string]PathItemOrRef)(c))
+}
+
+// unmarshalItems decodifica o objeto b em items, sem as chaves "x-*".
+func unmarshalItems[T any](b []byte, items *map[string]T) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*items = nil
+		return nil
+	}
+	*items = make(map[string]T, len(raw))
+	for key, value := range raw {
+		if isExtension(key) {
+			continue
+		}
+		var item T
+		if err := json.Unmarshal(value, &item); err != nil {
+			return err
+		}
+		(*items)[key] = item
+	}
+	return nil
+}
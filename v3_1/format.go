@@ -0,0 +1,219 @@
+package oas
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ========== Formatos (Schema.Format) ==========
+
+// FormatChecker confere se um valor atende a um formato. Valores de tipos aos
+// quais o formato não se aplica (ex.: número para "email") devem ser aceitos.
+type FormatChecker interface {
+	CheckFormat(value any) error
+}
+
+// FormatCheckerFunc adapta uma função a FormatChecker.
+type FormatCheckerFunc func(value any) error
+
+func (f FormatCheckerFunc) CheckFormat(value any) error {
+	return f(value)
+}
+
+// StringFormat cria um FormatChecker que só confere strings.
+func StringFormat(check func(s string) error) FormatChecker {
+	return FormatCheckerFunc(func(value any) error {
+		if s, ok := value.(string); ok {
+			return check(s)
+		}
+		return nil
+	})
+}
+
+// numberFormat cria um FormatChecker que só confere números.
+func numberFormat(check func(n json.Number) error) FormatChecker {
+	return FormatCheckerFunc(func(value any) error {
+		if n, ok := value.(json.Number); ok {
+			return check(n)
+		}
+		return nil
+	})
+}
+
+var formats = struct {
+	sync.RWMutex
+	checkers map[string]FormatChecker
+}{checkers: builtinFormats()}
+
+// RegisterFormat registra (ou substitui) um formato para todos os
+// SchemaValidator; checker nil remove o formato. Ex.: RegisterFormat("cpf", ...).
+func RegisterFormat(name string, checker FormatChecker) {
+	formats.Lock()
+	defer formats.Unlock()
+	if checker == nil {
+		delete(formats.checkers, name)
+		return
+	}
+	formats.checkers[name] = checker
+}
+
+func registeredFormat(name string) FormatChecker {
+	formats.RLock()
+	defer formats.RUnlock()
+	return formats.checkers[name]
+}
+
+var errFormat = errors.New("formato inválido")
+
+var (
+	reDuration = regexp.MustCompile(`^P(\d+W|(\d+Y)?(\d+M)?(\d+D)?(T(\d+H)?(\d+M)?(\d+S)?)?)$`)
+	reUUID     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	reHostname = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+)
+
+func builtinFormats() map[string]FormatChecker {
+	layout := func(layouts ...string) FormatChecker {
+		return StringFormat(func(s string) error {
+			var err error
+			for _, l := range layouts {
+				if _, err = time.Parse(l, s); err == nil {
+					return nil
+				}
+			}
+			return err
+		})
+	}
+	matches := func(re *regexp.Regexp) FormatChecker {
+		return StringFormat(func(s string) error {
+			if !re.MatchString(s) {
+				return errFormat
+			}
+			return nil
+		})
+	}
+	anything := FormatCheckerFunc(func(any) error { return nil })
+
+	return map[string]FormatChecker{
+		"date-time": layout(time.RFC3339Nano),
+		"date":      layout(time.DateOnly),
+		"time":      layout("15:04:05.999999999Z07:00"),
+		"duration": StringFormat(func(s string) error {
+			if !reDuration.MatchString(s) || s == "P" || strings.HasSuffix(s, "T") {
+				return errFormat
+			}
+			return nil
+		}),
+		"email":     StringFormat(func(s string) error { return checkEmail(s, true) }),
+		"idn-email": StringFormat(func(s string) error { return checkEmail(s, false) }),
+		"hostname":  StringFormat(checkHostname),
+		"ipv4":      StringFormat(func(s string) error { return checkIP(s, true) }),
+		"ipv6":      StringFormat(func(s string) error { return checkIP(s, false) }),
+		"uri": StringFormat(func(s string) error {
+			u, err := url.Parse(s)
+			if err != nil {
+				return err
+			}
+			if !u.IsAbs() {
+				return errors.New("URI sem scheme")
+			}
+			return nil
+		}),
+		"uri-reference": StringFormat(func(s string) error {
+			_, err := url.Parse(s)
+			return err
+		}),
+		"uuid": matches(reUUID),
+		"regex": StringFormat(func(s string) error {
+			_, err := regexp.Compile(s)
+			return err
+		}),
+		"int32":  numberFormat(func(n json.Number) error { return checkInt(n, 32) }),
+		"int64":  numberFormat(func(n json.Number) error { return checkInt(n, 64) }),
+		"float":  numberFormat(func(n json.Number) error { return checkFloat(n, 32) }),
+		"double": numberFormat(func(n json.Number) error { return checkFloat(n, 64) }),
+		"byte": StringFormat(func(s string) error {
+			_, err := base64.StdEncoding.DecodeString(s)
+			return err
+		}),
+		"binary":   anything,
+		"password": anything,
+	}
+}
+
+func checkEmail(s string, ascii bool) error {
+	if ascii {
+		for _, r := range s {
+			if r >= utf8.RuneSelf {
+				return errors.New("caractere não ASCII")
+			}
+		}
+	}
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return err
+	}
+	if addr.Address != s {
+		return errFormat
+	}
+	return nil
+}
+
+func checkHostname(s string) error {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return errFormat
+	}
+	for _, label := range strings.Split(s, ".") {
+		if !reHostname.MatchString(label) {
+			return fmt.Errorf("rótulo %q inválido", label)
+		}
+	}
+	return nil
+}
+
+func checkIP(s string, v4 bool) error {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return err
+	}
+	if addr.Is4() != v4 || addr.Zone() != "" {
+		return errFormat
+	}
+	return nil
+}
+
+func checkInt(n json.Number, bits uint) error {
+	r, ok := new(big.Rat).SetString(n.String())
+	if !ok || !r.IsInt() {
+		return errors.New("não é inteiro")
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), bits-1)
+	if v := r.Num(); v.Cmp(new(big.Int).Neg(limit)) < 0 || v.Cmp(limit) >= 0 {
+		return fmt.Errorf("fora do intervalo de int%d", bits)
+	}
+	return nil
+}
+
+func checkFloat(n json.Number, bits int) error {
+	f, err := strconv.ParseFloat(n.String(), bits)
+	if err != nil {
+		return fmt.Errorf("fora do intervalo de float%d", bits)
+	}
+	if math.IsInf(f, 0) {
+		return fmt.Errorf("fora do intervalo de float%d", bits)
+	}
+	return nil
+}
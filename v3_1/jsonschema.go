@@ -0,0 +1,759 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ========== Validação de instâncias (JSON Schema 2020-12) ==========
+
+// SchemaError descreve uma falha de uma instância contra um schema.
+type SchemaError struct {
+	InstancePointer string // JSON Pointer do valor inválido na instância ("" é a raiz)
+	SchemaPointer   string // keyword que falhou, a partir do schema raiz ("$ref" entra no caminho ao ser seguido)
+	Keyword         string
+	Message         string
+}
+
+func (e *SchemaError) Error() string {
+	ptr := e.InstancePointer
+	if ptr == "" {
+		ptr = "(raiz)"
+	}
+	return fmt.Sprintf("%s: %s (schema %s)", ptr, e.Message, e.SchemaPointer)
+}
+
+func newSchemaError(iptr, sptr, keyword, format string, args ...any) *SchemaError {
+	return &SchemaError{
+		InstancePointer: iptr,
+		SchemaPointer:   sptr + "/" + keyword,
+		Keyword:         keyword,
+		Message:         fmt.Sprintf(format, args...),
+	}
+}
+
+// SchemaErrors agrega todas as falhas de uma validação.
+type SchemaErrors []*SchemaError
+
+func (es SchemaErrors) Error() string {
+	lines := make([]string, len(es))
+	for i, e := range es {
+		lines[i] = e.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (es SchemaErrors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
+// SchemaValidator valida instâncias JSON contra schemas do documento do
+// Resolver. $ref são seguidos pelo Resolver (inclusive externos, se ele tiver
+// RefLoader). É seguro para uso concorrente.
+type SchemaValidator struct {
+	r             *Resolver
+	formats       map[string]FormatChecker // formatos só deste validador
+	assertFormats bool
+	mu            sync.Mutex
+	schemas       map[string]SchemaOrRef // $ref -> alvo decodificado
+	patterns      map[string]*regexp.Regexp
+}
+
+// SchemaValidatorOption configura um SchemaValidator.
+type SchemaValidatorOption func(*SchemaValidator)
+
+// WithFormatAssertion faz "format" reprovar valores fora do formato. Por
+// padrão, como no JSON Schema 2020-12, format é só anotação. Formatos
+// desconhecidos são sempre ignorados.
+func WithFormatAssertion(assert bool) SchemaValidatorOption {
+	return func(v *SchemaValidator) { v.assertFormats = assert }
+}
+
+// WithFormat registra um formato só para este validador, com precedência
+// sobre RegisterFormat e os embutidos; checker nil desativa o formato.
+func WithFormat(name string, checker FormatChecker) SchemaValidatorOption {
+	return func(v *SchemaValidator) { v.formats[name] = checker }
+}
+
+// NewSchemaValidator cria um validador que resolve $ref por r.
+func NewSchemaValidator(r *Resolver, opts ...SchemaValidatorOption) *SchemaValidator {
+	v := &SchemaValidator{
+		r:        r,
+		formats:  map[string]FormatChecker{},
+		schemas:  map[string]SchemaOrRef{},
+		patterns: map[string]*regexp.Regexp{},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+func (v *SchemaValidator) format(name string) FormatChecker {
+	if checker, ok := v.formats[name]; ok {
+		return checker
+	}
+	return registeredFormat(name)
+}
+
+// Validate valida instance (qualquer valor serializável em JSON) contra
+// schema. Devolve nil, SchemaErrors com todas as falhas, ou outro erro se um
+// $ref não puder ser resolvido.
+func (v *SchemaValidator) Validate(schema SchemaOrRef, instance any) error {
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return err
+	}
+	return v.ValidateJSON(schema, data)
+}
+
+// ValidateJSON é como Validate, para uma instância já em JSON.
+func (v *SchemaValidator) ValidateJSON(schema SchemaOrRef, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var instance any
+	if err := dec.Decode(&instance); err != nil {
+		return err
+	}
+	ev := &evaluation{v: v, active: map[string]bool{}}
+	errs, err := ev.schemaOrRef(schema, instance, "", "")
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// evaluation guarda o estado de uma chamada de Validate.
+type evaluation struct {
+	v      *SchemaValidator
+	active map[string]bool // $ref + ponteiro da instância em avaliação, contra ciclos
+}
+
+// resolve segue um único $ref; o alvo pode ser outro $ref.
+func (v *SchemaValidator) resolve(ref string) (SchemaOrRef, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok := v.schemas[ref]; ok {
+		return s, nil
+	}
+	if v.r == nil {
+		return SchemaOrRef{}, &RefError{Ref: ref, Err: ErrExternalRef}
+	}
+	var s SchemaOrRef
+	if err := v.r.follow(ref, map[string]bool{}, &s); err != nil {
+		return SchemaOrRef{}, err
+	}
+	v.schemas[ref] = s
+	return s, nil
+}
+
+func (v *SchemaValidator) pattern(expr string) (*regexp.Regexp, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if re, ok := v.patterns[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns[expr] = re
+	return re, nil
+}
+
+// annotations registra o que já foi avaliado num valor (propriedades e índices),
+// para unevaluatedProperties e unevaluatedItems.
+type annotations struct {
+	props map[string]bool
+	items map[int]bool
+}
+
+func newAnnotations() *annotations {
+	return &annotations{props: map[string]bool{}, items: map[int]bool{}}
+}
+
+func (a *annotations) merge(b *annotations) {
+	if b == nil {
+		return
+	}
+	for k := range b.props {
+		a.props[k] = true
+	}
+	for i := range b.items {
+		a.items[i] = true
+	}
+}
+
+func (ev *evaluation) schemaOrRef(s SchemaOrRef, inst any, iptr, sptr string) (SchemaErrors, error) {
+	errs, _, err := ev.annotated(s, inst, iptr, sptr)
+	return errs, err
+}
+
+// annotated avalia s e devolve também as anotações produzidas sobre inst.
+func (ev *evaluation) annotated(s SchemaOrRef, inst any, iptr, sptr string) (SchemaErrors, *annotations, error) {
+	ann := newAnnotations()
+	switch {
+	case s.Bool != nil:
+		if *s.Bool {
+			return nil, ann, nil
+		}
+		return SchemaErrors{{InstancePointer: iptr, SchemaPointer: sptr, Keyword: "false", Message: "schema false não aceita nenhum valor"}}, ann, nil
+	case s.Ref == nil:
+		if s.Schema == nil {
+			return nil, ann, nil
+		}
+		return ev.schema(s.Schema, inst, iptr, sptr, ann)
+	}
+
+	// $ref, com os keywords irmãos avaliados sobre o mesmo valor
+	errs, err := ev.ref(s.Ref.Ref, inst, iptr, sptr+"/$ref", ann)
+	if err != nil || s.Schema == nil {
+		return errs, ann, err
+	}
+	sub, _, err := ev.schema(s.Schema, inst, iptr, sptr, ann)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(errs, sub...), ann, nil
+}
+
+func (ev *evaluation) ref(ref string, inst any, iptr, sptr string, ann *annotations) (SchemaErrors, error) {
+	key := ref + " " + iptr
+	if ev.active[key] {
+		// o mesmo $ref sobre o mesmo valor: não acrescenta nada
+		return nil, nil
+	}
+	target, err := ev.v.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	ev.active[key] = true
+	defer delete(ev.active, key)
+	return ev.inPlace(target, inst, iptr, sptr, ann)
+}
+
+// valid informa se inst satisfaz s, sem acumular as falhas.
+func (ev *evaluation) valid(s SchemaOrRef, inst any, iptr, sptr string) (bool, error) {
+	errs, err := ev.schemaOrRef(s, inst, iptr, sptr)
+	return len(errs) == 0, err
+}
+
+// inPlace avalia um subschema sobre o mesmo valor; as anotações só contam se
+// ele for satisfeito.
+func (ev *evaluation) inPlace(s SchemaOrRef, inst any, iptr, sptr string, ann *annotations) (SchemaErrors, error) {
+	errs, sub, err := ev.annotated(s, inst, iptr, sptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) == 0 {
+		ann.merge(sub)
+	}
+	return errs, nil
+}
+
+// schema avalia s sobre inst, acumulando as anotações em ann.
+func (ev *evaluation) schema(s *Schema, inst any, iptr, sptr string, ann *annotations) (SchemaErrors, *annotations, error) {
+	var errs SchemaErrors
+
+	// tipo, enum e const
+	if s.Type != nil {
+		types := s.Type.Many
+		if s.Type.One != nil {
+			types = []string{*s.Type.One}
+		}
+		ok := false
+		for _, t := range types {
+			ok = ok || instanceHasType(inst, t)
+		}
+		if !ok {
+			errs = append(errs, newSchemaError(iptr, sptr, "type", "tipo %s não permitido (esperado %s)", instanceType(inst), strings.Join(types, ", ")))
+		}
+	}
+	if s.Enum != nil {
+		ok := false
+		for _, e := range s.Enum {
+			ok = ok || jsonEqual(inst, e)
+		}
+		if !ok {
+			errs = append(errs, newSchemaError(iptr, sptr, "enum", "valor fora de enum"))
+		}
+	}
+	if s.Const != nil && !jsonEqual(inst, s.Const) {
+		errs = append(errs, newSchemaError(iptr, sptr, "const", "valor diferente de const"))
+	}
+	if s.Format != nil && ev.v.assertFormats {
+		if checker := ev.v.format(*s.Format); checker != nil {
+			if err := checker.CheckFormat(inst); err != nil {
+				errs = append(errs, newSchemaError(iptr, sptr, "format", "formato %q inválido: %v", *s.Format, err))
+			}
+		}
+	}
+
+	// combinações
+	for i, sub := range s.AllOf {
+		failures, err := ev.inPlace(sub, inst, iptr, ptrJoin(sptr, "allOf", strconv.Itoa(i)), ann)
+		if err != nil {
+			return nil, nil, err
+		}
+		errs = append(errs, failures...)
+	}
+	if len(s.AnyOf) > 0 {
+		matched := 0
+		for i, sub := range s.AnyOf {
+			failures, err := ev.inPlace(sub, inst, iptr, ptrJoin(sptr, "anyOf", strconv.Itoa(i)), ann)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(failures) == 0 {
+				matched++
+			}
+		}
+		if matched == 0 {
+			errs = append(errs, newSchemaError(iptr, sptr, "anyOf", "não satisfaz nenhum schema de anyOf"))
+		}
+	}
+	if len(s.OneOf) > 0 {
+		matched := 0
+		for i, sub := range s.OneOf {
+			failures, err := ev.inPlace(sub, inst, iptr, ptrJoin(sptr, "oneOf", strconv.Itoa(i)), ann)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(failures) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			errs = append(errs, newSchemaError(iptr, sptr, "oneOf", "deve satisfazer exatamente um schema de oneOf (satisfez %d)", matched))
+		}
+	}
+	for i, sub := range s.Not {
+		ok, err := ev.valid(sub, inst, iptr, ptrJoin(sptr, "not", strconv.Itoa(i)))
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			e := newSchemaError(iptr, sptr, "not", "não deveria satisfazer o schema de not")
+			e.SchemaPointer = ptrJoin(sptr, "not", strconv.Itoa(i))
+			errs = append(errs, e)
+		}
+	}
+
+	// if/then/else
+	if s.If != nil {
+		failures, err := ev.inPlace(*s.If, inst, iptr, sptr+"/if", ann)
+		if err != nil {
+			return nil, nil, err
+		}
+		branch, where := s.Then, "/then"
+		if len(failures) > 0 {
+			branch, where = s.Else, "/else"
+		}
+		if branch != nil {
+			failures, err := ev.inPlace(*branch, inst, iptr, sptr+where, ann)
+			if err != nil {
+				return nil, nil, err
+			}
+			errs = append(errs, failures...)
+		}
+	}
+
+	var (
+		sub SchemaErrors
+		err error
+	)
+	switch x := inst.(type) {
+	case map[string]any:
+		sub, err = ev.object(s, x, iptr, sptr, ann)
+	case []any:
+		sub, err = ev.array(s, x, iptr, sptr, ann)
+	case string:
+		sub, err = ev.str(s, x, iptr, sptr)
+	case json.Number:
+		sub = ev.number(s, x, iptr, sptr)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	errs = append(errs, sub...)
+
+	// unevaluated* por último: dependem das anotações de todo o resto
+	sub = nil
+	switch x := inst.(type) {
+	case map[string]any:
+		sub, err = ev.unevaluatedProperties(s, x, iptr, sptr, ann)
+	case []any:
+		sub, err = ev.unevaluatedItems(s, x, iptr, sptr, ann)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(errs, sub...), ann, nil
+}
+
+func (ev *evaluation) object(s *Schema, obj map[string]any, iptr, sptr string, ann *annotations) (SchemaErrors, error) {
+	var errs SchemaErrors
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			errs = append(errs, newSchemaError(iptr, sptr, "required", "propriedade obrigatória %q ausente", name))
+		}
+	}
+	if s.MinProperties != nil && len(obj) < *s.MinProperties {
+		errs = append(errs, newSchemaError(iptr, sptr, "minProperties", "deve ter ao menos %d propriedades", *s.MinProperties))
+	}
+	if s.MaxProperties != nil && len(obj) > *s.MaxProperties {
+		errs = append(errs, newSchemaError(iptr, sptr, "maxProperties", "deve ter no máximo %d propriedades", *s.MaxProperties))
+	}
+	for _, name := range sortedKeys(s.DependentRequired) {
+		if _, ok := obj[name]; !ok {
+			continue
+		}
+		for _, dep := range s.DependentRequired[name] {
+			if _, ok := obj[dep]; !ok {
+				errs = append(errs, newSchemaError(iptr, sptr, "dependentRequired", "propriedade %q exigida por %q ausente", dep, name))
+			}
+		}
+	}
+	for _, name := range sortedKeys(s.DependentSchemas) {
+		if _, ok := obj[name]; !ok {
+			continue
+		}
+		sub, err := ev.inPlace(s.DependentSchemas[name], obj, iptr, ptrJoin(sptr, "dependentSchemas", name), ann)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, sub...)
+	}
+	for _, name := range sortedKeys(obj) {
+		value := obj[name]
+		vptr := ptrJoin(iptr, name)
+		if s.PropertyNames != nil {
+			ok, err := ev.valid(*s.PropertyNames, name, vptr, sptr+"/propertyNames")
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				errs = append(errs, newSchemaError(vptr, sptr, "propertyNames", "nome de propriedade %q inválido", name))
+			}
+		}
+		evaluated := false
+		if prop, ok := s.Properties[name]; ok {
+			evaluated = true
+			sub, err := ev.schemaOrRef(prop, value, vptr, ptrJoin(sptr, "properties", name))
+			if err != nil {
+				return nil, err
+			}
+			errs = append(errs, sub...)
+		}
+		for _, expr := range sortedKeys(s.PatternProperties) {
+			re, err := ev.v.pattern(expr)
+			if err != nil {
+				return nil, fmt.Errorf("patternProperties %q: %w", expr, err)
+			}
+			if !re.MatchString(name) {
+				continue
+			}
+			evaluated = true
+			sub, err := ev.schemaOrRef(s.PatternProperties[expr], value, vptr, ptrJoin(sptr, "patternProperties", expr))
+			if err != nil {
+				return nil, err
+			}
+			errs = append(errs, sub...)
+		}
+		if evaluated || s.AdditionalProperties == nil {
+			if evaluated {
+				ann.props[name] = true
+			}
+			continue
+		}
+		ann.props[name] = true
+		sub, err := ev.boolOrSchema(s.AdditionalProperties, value, vptr, sptr, "additionalProperties", "propriedade %q não permitida", name)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, sub...)
+	}
+	return errs, nil
+}
+
+// boolOrSchema aplica um keyword boolean-ou-schema (additionalProperties,
+// unevaluated*) a um valor; false reprova com a mensagem dada.
+func (ev *evaluation) boolOrSchema(ap *AdditionalProperties, value any, vptr, sptr, keyword, format string, args ...any) (SchemaErrors, error) {
+	switch {
+	case ap.Allows != nil && !*ap.Allows:
+		return SchemaErrors{newSchemaError(vptr, sptr, keyword, format, args...)}, nil
+	case ap.Schema != nil:
+		return ev.schemaOrRef(*ap.Schema, value, vptr, ptrJoin(sptr, keyword))
+	}
+	return nil, nil
+}
+
+func (ev *evaluation) unevaluatedProperties(s *Schema, obj map[string]any, iptr, sptr string, ann *annotations) (SchemaErrors, error) {
+	if s.UnevaluatedProperties == nil {
+		return nil, nil
+	}
+	var errs SchemaErrors
+	for _, name := range sortedKeys(obj) {
+		if ann.props[name] {
+			continue
+		}
+		ann.props[name] = true
+		sub, err := ev.boolOrSchema(s.UnevaluatedProperties, obj[name], ptrJoin(iptr, name), sptr, "unevaluatedProperties", "propriedade %q não avaliada", name)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, sub...)
+	}
+	return errs, nil
+}
+
+func (ev *evaluation) unevaluatedItems(s *Schema, arr []any, iptr, sptr string, ann *annotations) (SchemaErrors, error) {
+	if s.UnevaluatedItems == nil {
+		return nil, nil
+	}
+	var errs SchemaErrors
+	for i, item := range arr {
+		if ann.items[i] {
+			continue
+		}
+		ann.items[i] = true
+		sub, err := ev.boolOrSchema(s.UnevaluatedItems, item, ptrJoin(iptr, strconv.Itoa(i)), sptr, "unevaluatedItems", "item %d não avaliado", i)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, sub...)
+	}
+	return errs, nil
+}
+
+func (ev *evaluation) array(s *Schema, arr []any, iptr, sptr string, ann *annotations) (SchemaErrors, error) {
+	var errs SchemaErrors
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		errs = append(errs, newSchemaError(iptr, sptr, "minItems", "deve ter ao menos %d itens", *s.MinItems))
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		errs = append(errs, newSchemaError(iptr, sptr, "maxItems", "deve ter no máximo %d itens", *s.MaxItems))
+	}
+	if s.UniqueItems != nil && *s.UniqueItems {
+	unique:
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					errs = append(errs, newSchemaError(iptr, sptr, "uniqueItems", "itens %d e %d são iguais", i, j))
+					break unique
+				}
+			}
+		}
+	}
+
+	// prefixItems (ou items em forma de lista) e depois items para o restante
+	prefix, prefixKeyword := []SchemaOrRef(s.PrefixItems), "prefixItems"
+	if s.Items != nil && s.Items.List != nil {
+		prefix, prefixKeyword = s.Items.List, "items"
+	}
+	for i, item := range arr {
+		var (
+			sch   SchemaOrRef
+			where string
+		)
+		switch {
+		case i < len(prefix):
+			sch, where = prefix[i], ptrJoin(sptr, prefixKeyword, strconv.Itoa(i))
+		case s.Items != nil && s.Items.Single != nil:
+			sch, where = *s.Items.Single, sptr+"/items"
+		default:
+			continue
+		}
+		ann.items[i] = true
+		sub, err := ev.schemaOrRef(sch, item, ptrJoin(iptr, strconv.Itoa(i)), where)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, sub...)
+	}
+
+	if s.Contains != nil {
+		matched := 0
+		for i, item := range arr {
+			ok, err := ev.valid(*s.Contains, item, ptrJoin(iptr, strconv.Itoa(i)), sptr+"/contains")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched++
+				ann.items[i] = true
+			}
+		}
+		min := 1
+		if s.MinContains != nil {
+			min = *s.MinContains
+		}
+		if matched < min {
+			keyword := "contains"
+			if s.MinContains != nil {
+				keyword = "minContains"
+			}
+			errs = append(errs, newSchemaError(iptr, sptr, keyword, "deve conter ao menos %d itens que satisfaçam contains (tem %d)", min, matched))
+		}
+		if s.MaxContains != nil && matched > *s.MaxContains {
+			errs = append(errs, newSchemaError(iptr, sptr, "maxContains", "deve conter no máximo %d itens que satisfaçam contains (tem %d)", *s.MaxContains, matched))
+		}
+	}
+	return errs, nil
+}
+
+func (ev *evaluation) str(s *Schema, str string, iptr, sptr string) (SchemaErrors, error) {
+	var errs SchemaErrors
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		errs = append(errs, newSchemaError(iptr, sptr, "minLength", "deve ter ao menos %d caracteres", *s.MinLength))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		errs = append(errs, newSchemaError(iptr, sptr, "maxLength", "deve ter no máximo %d caracteres", *s.MaxLength))
+	}
+	if s.Pattern != nil {
+		re, err := ev.v.pattern(*s.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", *s.Pattern, err)
+		}
+		if !re.MatchString(str) {
+			errs = append(errs, newSchemaError(iptr, sptr, "pattern", "não corresponde a %q", *s.Pattern))
+		}
+	}
+	return errs, nil
+}
+
+func (ev *evaluation) number(s *Schema, n json.Number, iptr, sptr string) SchemaErrors {
+	var errs SchemaErrors
+	value, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		return nil
+	}
+	check := func(keyword string, bound *float64, holds func(c int) bool, format string) {
+		if bound == nil {
+			return
+		}
+		if !holds(value.Cmp(decimalRat(*bound))) {
+			errs = append(errs, newSchemaError(iptr, sptr, keyword, format, *bound))
+		}
+	}
+	check("minimum", s.Minimum, func(c int) bool { return c >= 0 }, "deve ser >= %v")
+	check("exclusiveMinimum", s.ExclusiveMinimum, func(c int) bool { return c > 0 }, "deve ser > %v")
+	check("maximum", s.Maximum, func(c int) bool { return c <= 0 }, "deve ser <= %v")
+	check("exclusiveMaximum", s.ExclusiveMaximum, func(c int) bool { return c < 0 }, "deve ser < %v")
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		q := new(big.Rat).Quo(value, decimalRat(*s.MultipleOf))
+		if !q.IsInt() {
+			errs = append(errs, newSchemaError(iptr, sptr, "multipleOf", "deve ser múltiplo de %v", *s.MultipleOf))
+		}
+	}
+	return errs
+}
+
+// decimalRat converte f pelo seu menor decimal (0.1 vira 1/10, não o binário
+// mais próximo), para que multipleOf e limites decimais sejam exatos.
+func decimalRat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
+
+func instanceType(inst any) string {
+	switch x := inst.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(x.String()); ok && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", inst)
+}
+
+func instanceHasType(inst any, t string) bool {
+	actual := instanceType(inst)
+	return actual == t || (t == "number" && actual == "integer")
+}
+
+// jsonEqual compara dois valores JSON: números pelo valor (1 == 1.0) e
+// objetos sem considerar a ordem das chaves.
+func jsonEqual(a, b any) bool {
+	a, b = normalizeJSON(a), normalizeJSON(b)
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		rx, okx := new(big.Rat).SetString(x.String())
+		ry, oky := new(big.Rat).SetString(y.String())
+		return okx && oky && rx.Cmp(ry) == 0
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !jsonEqual(xv, yv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// normalizeJSON leva valores Go (ex.: enum vindo do Builder) à forma
+// decodificada com UseNumber.
+func normalizeJSON(v any) any {
+	switch v.(type) {
+	case nil, bool, string, json.Number, []any, map[string]any:
+		return v
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return v
+	}
+	return out
+}
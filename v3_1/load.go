@@ -0,0 +1,291 @@
+package oas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ========== Carregamento de documentos ==========
+
+// Format identifica a serialização de um documento.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+var (
+	// ErrMissingVersion indica que o campo "openapi" está ausente ou vazio.
+	ErrMissingVersion = errors.New("campo openapi ausente")
+	// ErrUnsupportedVersion indica um documento que não é OpenAPI 3.1.x.
+	ErrUnsupportedVersion = errors.New("versão openapi não suportada")
+)
+
+var reOpenAPI31 = regexp.MustCompile(`^3\.1\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// VersionError informa a versão encontrada quando ela não é 3.1.x.
+// Satisfaz errors.Is(err, ErrUnsupportedVersion).
+type VersionError struct {
+	Version string
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("versão openapi %q não suportada (esperado 3.1.x)", e.Version)
+}
+
+func (e *VersionError) Is(target error) bool {
+	return target == ErrUnsupportedVersion
+}
+
+// LoadError envolve qualquer falha de Load com o nome da origem e o formato detectado.
+type LoadError struct {
+	Source string
+	Format Format
+	Err    error
+}
+
+func (e *LoadError) Error() string {
+	name := e.Source
+	if name == "" {
+		name = "<documento>"
+	}
+	if e.Format != "" {
+		return fmt.Sprintf("%s (%s): %v", name, e.Format, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", name, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// Source é a origem de um documento: arquivo, bytes, io.Reader ou fs.FS.
+type Source interface {
+	// Read devolve o conteúdo bruto e o nome da origem (usado em erros e na
+	// detecção de formato pela extensão).
+	Read(ctx context.Context) ([]byte, string, error)
+}
+
+type fileSource string
+
+func (s fileSource) Read(ctx context.Context) ([]byte, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, string(s), err
+	}
+	b, err := os.ReadFile(string(s))
+	return b, string(s), err
+}
+
+// FromFile lê o documento de um caminho no sistema de arquivos.
+func FromFile(path string) Source {
+	return fileSource(path)
+}
+
+type bytesSource struct {
+	name string
+	data []byte
+}
+
+func (s bytesSource) Read(ctx context.Context) ([]byte, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, s.name, err
+	}
+	return s.data, s.name, nil
+}
+
+// FromBytes usa um conteúdo já em memória. O nome é opcional.
+func FromBytes(data []byte, name ...string) Source {
+	return bytesSource{name: strings.Join(name, ""), data: data}
+}
+
+type readerSource struct {
+	name string
+	r    io.Reader
+}
+
+func (s readerSource) Read(ctx context.Context) ([]byte, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, s.name, err
+	}
+	b, err := io.ReadAll(s.r)
+	if err != nil {
+		return nil, s.name, err
+	}
+	return b, s.name, ctx.Err()
+}
+
+// FromReader lê o documento de um io.Reader. O nome é opcional.
+func FromReader(r io.Reader, name ...string) Source {
+	return readerSource{name: strings.Join(name, ""), r: r}
+}
+
+type fsSource struct {
+	fsys fs.FS
+	name string
+}
+
+func (s fsSource) Read(ctx context.Context) ([]byte, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, s.name, err
+	}
+	b, err := fs.ReadFile(s.fsys, s.name)
+	return b, s.name, err
+}
+
+// FromFS lê o documento de um fs.FS (ex.: embed.FS).
+func FromFS(fsys fs.FS, name string) Source {
+	return fsSource{fsys: fsys, name: name}
+}
+
+// LoadOption configura Load, LoadYAML e Unmarshal.
+type LoadOption func(*loadConfig)
+
+type loadConfig struct {
+	sourceMap bool
+	strict    bool
+}
+
+// WithSourceMap registra a posição (arquivo:linha:coluna) de cada nó do
+// documento, disponível em Document.SourceMap e anexada aos erros de
+// decodificação, de Validate e de Bundle/Dereference.
+func WithSourceMap() LoadOption {
+	return func(c *loadConfig) { c.sourceMap = true }
+}
+
+// WithStrict rejeita campos desconhecidos (exceto extensões "x-*"), comuns
+// em erros de digitação como "requried". Cada campo vira um ValidationError
+// (CodeUnknownField) com seu JSON Pointer.
+func WithStrict() LoadOption {
+	return func(c *loadConfig) { c.strict = true }
+}
+
+func newLoadConfig(opts []LoadOption) loadConfig {
+	var cfg loadConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Load lê, detecta o formato (JSON ou YAML), confere a versão 3.1.x e
+// decodifica o documento. Erros são sempre *LoadError.
+func Load(ctx context.Context, src Source, opts ...LoadOption) (*Document, error) {
+	data, name, err := src.Read(ctx)
+	if err != nil {
+		return nil, &LoadError{Source: name, Err: err}
+	}
+	data = trimBOM(data)
+	format := DetectFormat(name, data)
+	doc, err := decodeDocument(data, format, name, newLoadConfig(opts))
+	if err != nil {
+		return nil, &LoadError{Source: name, Format: format, Err: err}
+	}
+	return doc, nil
+}
+
+// Unmarshal decodifica data (JSON ou YAML, detectado pelo conteúdo) em v: um
+// *Document ou qualquer outro objeto do pacote (*Schema, *Operation, ...).
+// Aceita as opções de Load (ex.: WithStrict, WithSourceMap) e, como
+// LoadYAML, não confere a versão.
+func Unmarshal(data []byte, v any, opts ...LoadOption) error {
+	cfg := newLoadConfig(opts)
+	data = trimBOM(data)
+	format := DetectFormat("", data)
+	raw := data
+	if format == FormatYAML {
+		var err error
+		if raw, err = yamlToJSON(data); err != nil {
+			return err
+		}
+	}
+	var sm SourceMap
+	if cfg.sourceMap {
+		var err error
+		if sm, err = buildSourceMap(data, format, ""); err != nil {
+			return err
+		}
+	}
+	if err := unmarshalValue(raw, v, sm, cfg.strict); err != nil {
+		return err
+	}
+	if doc, ok := v.(*Document); ok {
+		doc.sourceMap = sm
+	}
+	return nil
+}
+
+// DetectFormat decide o formato pela extensão do nome e, na falta dela, pelo
+// primeiro caractere significativo do conteúdo.
+func DetectFormat(name string, data []byte) Format {
+	ext := strings.ToLower(path.Ext(filepath.ToSlash(name)))
+	switch ext {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	trimmed := bytes.TrimLeft(trimBOM(data), " \t\r\n")
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// trimBOM remove o BOM UTF-8 do início de data: encoding/json não o aceita.
+func trimBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+}
+
+// decodeDocument converte o conteúdo para JSON (se preciso), valida a
+// versão antes do restante e decodifica o Document.
+func decodeDocument(data []byte, format Format, name string, cfg loadConfig) (*Document, error) {
+	raw := data
+	if format == FormatYAML {
+		var err error
+		if raw, err = yamlToJSON(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkVersion(raw); err != nil {
+		return nil, err
+	}
+	var sm SourceMap
+	if cfg.sourceMap {
+		var err error
+		if sm, err = buildSourceMap(data, format, name); err != nil {
+			return nil, err
+		}
+	}
+	return unmarshalDocument(raw, sm, cfg.strict)
+}
+
+func checkVersion(raw []byte) error {
+	var head struct {
+		OpenAPI any `json:"openapi"`
+	}
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return err
+	}
+	if head.OpenAPI == nil || head.OpenAPI == "" {
+		return ErrMissingVersion
+	}
+	v, ok := head.OpenAPI.(string)
+	if !ok {
+		return &VersionError{Version: fmt.Sprint(head.OpenAPI)}
+	}
+	if !reOpenAPI31.MatchString(v) {
+		return &VersionError{Version: v}
+	}
+	return nil
+}
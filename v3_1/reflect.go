@@ -0,0 +1,1152 @@
+package oas
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ========== Schemas a partir de tipos Go ==========
+
+// SchemaFor gera o Schema do tipo T (ver SchemaOf):
+// oas.SchemaOrRef{Schema: oas.SchemaFor[User]()}.
+func SchemaFor[T any]() *Schema {
+	return SchemaOf(reflect.TypeFor[T]())
+}
+
+// ReflectSchemaFor é SchemaFor devolvendo erro em vez de entrar em pânico.
+func ReflectSchemaFor[T any]() (*Schema, error) {
+	return ReflectSchema(reflect.TypeFor[T]())
+}
+
+// SchemaOf gera o Schema de t seguindo as regras de encoding/json:
+//
+//   - structs viram objetos; campos sem omitempty/omitzero são obrigatórios,
+//     campos de structs embutidas são promovidos e as tags json são
+//     respeitadas (nome, "-" e a opção string);
+//   - ponteiros também aceitam null (type ["x", "null"]);
+//   - slices e arrays viram arrays ([]byte é string base64) e mapas, objetos
+//     com additionalProperties;
+//   - time.Time é string date-time, [16]byte com MarshalText (ex.: uuid.UUID)
+//     é string uuid e outros TextMarshaler são string; json.RawMessage,
+//     interfaces e demais json.Marshaler aceitam qualquer valor.
+//
+// Tipos com OASSchema (SchemaProvider) descrevem o próprio schema, e os com
+// OASEnum (EnumProvider) ou TransformSchema (SchemaTransformer) o ajustam.
+// Tags validate:"..." (go-playground/validator) viram restrições (ver
+// applyValidate) e tags oas:"..." (ou jsonschema:"...") completam cada
+// campo (ver applyTags).
+//
+// Tipos recursivos ficam em $defs do schema gerado, que ganha um $id
+// ("urn:go-oas:<pacote>.<Nome>"), e são referenciados por essa URI: o $ref
+// continua válido com o schema embutido em qualquer ponto de um Document.
+// Para registrar os structs em components/schemas, use Builder.SchemaOf.
+//
+// Como regexp.MustCompile, SchemaOf entra em pânico se alguma tag oas,
+// jsonschema ou validate estiver malformada; ReflectSchema devolve o erro.
+func SchemaOf(t reflect.Type) *Schema {
+	s, err := ReflectSchema(t)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ReflectSchema é SchemaOf devolvendo os erros das tags (um por campo) em
+// vez de entrar em pânico.
+func ReflectSchema(t reflect.Type) (*Schema, error) {
+	r := newReflector(t)
+	for r.root.Kind() == reflect.Pointer {
+		r.root = r.root.Elem()
+	}
+	s := r.schema(t).Schema
+	if len(r.defs) > 0 {
+		s.Defs = r.defs
+	}
+	if len(r.recursive) > 0 {
+		s.ID = Ptr(schemaID(r.root))
+	}
+	if r.errs != nil {
+		return nil, errors.Join(r.errs...)
+	}
+	return s, nil
+}
+
+// schemaID é o $id do schema gerado para t.
+func schemaID(t reflect.Type) string {
+	name := DefaultSchemaNamer(t)
+	if t.Name() == "" {
+		name = argName(t.String(), func(base string, args []string) string {
+			return base + strings.Join(args, "")
+		})
+	}
+	if t.PkgPath() == "" {
+		return "urn:go-oas:" + name
+	}
+	return "urn:go-oas:" + t.PkgPath() + "." + name
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	numberType        = reflect.TypeFor[json.Number]()
+	marshalerType     = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// reflector guarda o estado de uma geração: os structs em andamento (para
+// detectar recursão), os que foram para $defs e os erros das tags (o campo
+// com erro fica sem a tag e a geração continua). Com registry, todo struct
+// nomeado vai para components/schemas.
+type reflector struct {
+	root      reflect.Type
+	active    map[reflect.Type]bool
+	recursive map[reflect.Type]bool
+	defs      Defs
+	registry  *schemaRegistry
+	errs      []error
+}
+
+func newReflector(root reflect.Type) *reflector {
+	return &reflector{root: root, active: map[reflect.Type]bool{}, recursive: map[reflect.Type]bool{}}
+}
+
+func typed(name string) *Schema {
+	return &Schema{Type: &StringOrArray{One: Ptr(name)}}
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+func (r *reflector) schema(t reflect.Type) SchemaOrRef {
+	if t.Kind() == reflect.Pointer {
+		return nullable(r.schema(t.Elem()))
+	}
+	s := r.typeSchema(t)
+	if s.Ref == nil && s.Schema != nil {
+		customize(t, s.Schema)
+	}
+	return s
+}
+
+// typeSchema gera o schema de um tipo que não é ponteiro. Os structs que
+// viram $ref passam por customize onde o schema deles é guardado.
+func (r *reflector) typeSchema(t reflect.Type) SchemaOrRef {
+	if v, ok := valueOf(t, schemaProviderType); ok {
+		if r.registry != nil && t.Kind() == reflect.Struct && t.Name() != "" {
+			return r.component(t)
+		}
+		s := v.(SchemaProvider).OASSchema()
+		return SchemaOrRef{Schema: &s}
+	}
+	switch {
+	case t == timeType:
+		s := typed("string")
+		s.Format = Ptr("date-time")
+		return SchemaOrRef{Schema: s}
+	case t == numberType:
+		return SchemaOrRef{Schema: typed("number")}
+	case t == rawMessageType, implements(t, marshalerType):
+		return SchemaOrRef{Schema: &Schema{}}
+	case implements(t, textMarshalerType):
+		s := typed("string")
+		if t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 {
+			s.Format = Ptr("uuid")
+		}
+		return SchemaOrRef{Schema: s}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return SchemaOrRef{Schema: typed("boolean")}
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		s := typed("integer")
+		if t.Kind() == reflect.Int32 {
+			s.Format = Ptr("int32")
+		} else if t.Kind() != reflect.Int16 && t.Kind() != reflect.Int8 {
+			s.Format = Ptr("int64")
+		}
+		return SchemaOrRef{Schema: s}
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uintptr:
+		s := typed("integer")
+		s.Minimum = Ptr(0.0)
+		return SchemaOrRef{Schema: s}
+	case reflect.Float32:
+		s := typed("number")
+		s.Format = Ptr("float")
+		return SchemaOrRef{Schema: s}
+	case reflect.Float64:
+		s := typed("number")
+		s.Format = Ptr("double")
+		return SchemaOrRef{Schema: s}
+	case reflect.String:
+		return SchemaOrRef{Schema: typed("string")}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !implements(t.Elem(), marshalerType) && !implements(t.Elem(), textMarshalerType) {
+			s := typed("string")
+			s.ContentEncoding = Ptr("base64")
+			return SchemaOrRef{Schema: s}
+		}
+		s := typed("array")
+		items := r.schema(t.Elem())
+		s.Items = &Items{Single: &items}
+		return SchemaOrRef{Schema: s}
+	case reflect.Array:
+		s := typed("array")
+		items := r.schema(t.Elem())
+		s.Items = &Items{Single: &items}
+		s.MinItems, s.MaxItems = Ptr(t.Len()), Ptr(t.Len())
+		return SchemaOrRef{Schema: s}
+	case reflect.Map:
+		s := typed("object")
+		values := r.schema(t.Elem())
+		s.AdditionalProperties = &AdditionalProperties{Schema: &values}
+		return SchemaOrRef{Schema: s}
+	case reflect.Struct:
+		return r.object(t)
+	}
+	// interfaces aceitam qualquer valor; chan, func e complex não têm JSON
+	return SchemaOrRef{Schema: &Schema{}}
+}
+
+// nullable acrescenta null aos valores aceitos por s. Type e Enum são
+// trocados, não alterados, pois podem ser compartilhados (ex.: TypeString
+// vindo de OASSchema).
+func nullable(s SchemaOrRef) SchemaOrRef {
+	switch {
+	case s.Ref != nil:
+		return SchemaOrRef{Schema: &Schema{AnyOf: AnyOf{s, {Schema: typed("null")}}}}
+	case s.Schema == nil || s.Schema.Type == nil:
+		return s
+	}
+	if s.Schema.Enum != nil {
+		s.Schema.Enum = append(s.Schema.Enum[:len(s.Schema.Enum):len(s.Schema.Enum)], nil)
+	}
+	t := s.Schema.Type
+	switch {
+	case t.One != nil:
+		s.Schema.Type = &StringOrArray{Many: []string{*t.One, "null"}}
+	case !contains(t.Many, "null"):
+		s.Schema.Type = &StringOrArray{Many: append(t.Many[:len(t.Many):len(t.Many)], "null")}
+	}
+	return s
+}
+
+// nullableEnum acrescenta null ao enum de um schema que aceita null: as tags
+// (enum, oneof) criam ou completam o enum depois de nullable.
+func nullableEnum(s *Schema) {
+	if s == nil || s.Enum == nil || s.Type == nil || !contains(s.Type.Many, "null") {
+		return
+	}
+	for _, v := range s.Enum {
+		if v == nil {
+			return
+		}
+	}
+	s.Enum = append(s.Enum[:len(s.Enum):len(s.Enum)], nil)
+}
+
+func (r *reflector) object(t reflect.Type) SchemaOrRef {
+	if r.registry != nil && t.Name() != "" {
+		return r.component(t)
+	}
+	if t.Name() != "" {
+		if r.active[t] || (r.recursive[t] && t != r.root) {
+			r.recursive[t] = true
+			return r.ref(t)
+		}
+		r.active[t] = true
+		defer delete(r.active, t)
+	}
+	s := r.fields(t)
+	if r.recursive[t] && t != r.root {
+		customize(t, s)
+		if r.defs == nil {
+			r.defs = Defs{}
+		}
+		r.defs[DefaultSchemaNamer(t)] = SchemaOrRef{Schema: s}
+		return r.ref(t)
+	}
+	return SchemaOrRef{Schema: s}
+}
+
+// fields monta o objeto com os campos de t.
+func (r *reflector) fields(t reflect.Type) *Schema {
+	s := typed("object")
+	for _, f := range structFields(t) {
+		fs := r.schema(f.typ)
+		if f.asString {
+			fs = SchemaOrRef{Schema: typed("string")}
+			if f.typ.Kind() == reflect.Pointer {
+				fs = nullable(fs)
+			}
+		}
+		required, err := applyValidate(&fs, f.typ, f.tag.Get("validate"))
+		if err == nil {
+			err = applyTags(&fs, f.tag)
+		}
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("oas: campo %s.%s: %w", t.Name(), f.goName, err))
+		}
+		nullableEnum(fs.Schema)
+		if s.Properties == nil {
+			s.Properties = Properties{}
+		}
+		s.Properties[f.name] = fs
+		if required || !f.optional {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+func (r *reflector) ref(t reflect.Type) SchemaOrRef {
+	ref := schemaID(r.root)
+	if t != r.root {
+		ref += ptrJoin("#/$defs", DefaultSchemaNamer(t))
+	}
+	return SchemaOrRef{Ref: &Reference{Ref: ref}}
+}
+
+// ---------------- Componentes -----------------
+
+// SchemaNamer dá o nome, em components/schemas, do schema de um tipo Go.
+// Nomes repetidos (ex.: users.User e billing.User) são qualificados pelo
+// pacote: User, billing.User.
+type SchemaNamer func(t reflect.Type) string
+
+// DefaultSchemaNamer usa o nome do tipo. Em instâncias de genéricos junta o
+// nome dos argumentos: Page[User] vira PageUser e Result[Order, Error],
+// ResultOrderError.
+var DefaultSchemaNamer = GenericSchemaNamer(func(base string, args []string) string {
+	return base + strings.Join(args, "")
+})
+
+// GenericSchemaNamer cria um SchemaNamer que usa join para montar o nome de
+// instâncias de genéricos, como base + "Of" + args. Os argumentos chegam já
+// nomeados, sem pacote e com inicial maiúscula: []User vira UserList,
+// map[string]User, MapStringUser, *User, User e genéricos aninhados passam
+// pelo mesmo join.
+func GenericSchemaNamer(join func(base string, args []string) string) SchemaNamer {
+	return func(t reflect.Type) string {
+		base, args, generic := strings.Cut(t.Name(), "[")
+		if !generic {
+			return sanitizeName(base)
+		}
+		return sanitizeName(join(base, genericArgs(args[:len(args)-1], join)))
+	}
+}
+
+// genericArgs nomeia os argumentos (separados por vírgula) de um genérico.
+func genericArgs(list string, join func(string, []string) string) []string {
+	var names []string
+	depth, start := 0, 0
+	for i, c := range list {
+		switch c {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				names = append(names, argName(list[start:i], join))
+				start = i + 1
+			}
+		}
+	}
+	return append(names, argName(list[start:], join))
+}
+
+// argName nomeia um tipo escrito como em reflect.Type.String, mas com o
+// caminho completo do pacote (github.com/x/users.User).
+func argName(name string, join func(string, []string) string) string {
+	name = strings.TrimSpace(name)
+	switch {
+	case strings.HasPrefix(name, "*"):
+		return argName(name[1:], join)
+	case strings.HasPrefix(name, "[]"):
+		return argName(name[2:], join) + "List"
+	case strings.HasPrefix(name, "["): // array [N]T
+		return argName(name[strings.Index(name, "]")+1:], join) + "List"
+	case strings.HasPrefix(name, "map["):
+		key, value := splitMap(name[len("map["):])
+		return "Map" + argName(key, join) + argName(value, join)
+	}
+	base, args, generic := strings.Cut(name, "[")
+	base = capitalize(base[strings.LastIndex(base, ".")+1:])
+	if !generic {
+		return base
+	}
+	return join(base, genericArgs(args[:len(args)-1], join))
+}
+
+// splitMap separa "K]V" (o que vem depois de "map[") em K e V.
+func splitMap(s string) (string, string) {
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			if depth == 0 {
+				return s[:i], s[i+1:]
+			}
+			depth--
+		}
+	}
+	return s, ""
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// sanitizeName deixa em name só o que um nome de componente aceita
+// (^[a-zA-Z0-9.\-_]+$).
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+// schemaRegistry guarda os tipos já registrados em components/schemas.
+type schemaRegistry struct {
+	components *Components
+	namer      SchemaNamer
+	names      map[reflect.Type]string
+	types      map[string]reflect.Type
+}
+
+func newSchemaRegistry(components *Components) *schemaRegistry {
+	return &schemaRegistry{
+		components: components,
+		namer:      DefaultSchemaNamer,
+		names:      map[reflect.Type]string{},
+		types:      map[string]reflect.Type{},
+	}
+}
+
+// name devolve o nome do componente de t, escolhendo um livre na primeira
+// vez: o do namer, depois qualificado pelo último elemento do pacote, pelo
+// caminho completo do pacote e, por fim, com sufixo numérico (User2).
+func (g *schemaRegistry) name(t reflect.Type) (string, bool) {
+	if name, ok := g.names[t]; ok {
+		return name, false
+	}
+	base := g.namer(t)
+	candidates := []string{base}
+	// qualificar só ajuda se o dono do nome vem de outro pacote
+	if pkg := t.PkgPath(); pkg != "" && (g.types[base] == nil || g.types[base].PkgPath() != pkg) {
+		candidates = append(candidates, path.Base(pkg)+"."+base, strings.ReplaceAll(pkg, "/", ".")+"."+base)
+	}
+	taken := func(name string) bool {
+		_, registered := g.types[name]
+		_, declared := g.components.Schemas[name]
+		return registered || declared
+	}
+	name := ""
+	for _, c := range candidates {
+		if !taken(c) {
+			name = c
+			break
+		}
+	}
+	// tipos locais de mesmo nome em um mesmo pacote
+	for i := 2; name == ""; i++ {
+		if c := fmt.Sprintf("%s%d", base, i); !taken(c) {
+			name = c
+		}
+	}
+	g.names[t], g.types[name] = name, t
+	return name, true
+}
+
+// component registra t em components/schemas (uma única vez), com os campos
+// ou o OASSchema do tipo, e devolve o $ref para ele; em tipos recursivos, o
+// $ref sai antes do schema ficar pronto.
+func (r *reflector) component(t reflect.Type) SchemaOrRef {
+	name, fresh := r.registry.name(t)
+	ref := SchemaOrRef{Ref: &Reference{Ref: ptrJoin("#/components/schemas", name)}}
+	if !fresh {
+		return ref
+	}
+	var s *Schema
+	if v, ok := valueOf(t, schemaProviderType); ok {
+		provided := v.(SchemaProvider).OASSchema()
+		s = &provided
+	} else {
+		s = r.fields(t)
+	}
+	customize(t, s)
+	if r.registry.components.Schemas == nil {
+		r.registry.components.Schemas = map[string]SchemaOrRef{}
+	}
+	r.registry.components.Schemas[name] = SchemaOrRef{Schema: s}
+	return ref
+}
+
+// ---------------- Schemas personalizados -----------------
+
+// SchemaProvider é implementado por tipos que descrevem o próprio schema, no
+// lugar do gerado por reflexão (ex.: decimal.Decimal como string decimal):
+//
+//	func (Money) OASSchema() oas.Schema {
+//		return oas.Schema{Type: oas.TypeString, Pattern: oas.Ptr(`^-?\d+\.\d{2}$`)}
+//	}
+type SchemaProvider interface {
+	OASSchema() Schema
+}
+
+// SchemaTransformer é implementado por tipos que ajustam o schema gerado
+// (por reflexão ou por OASSchema), depois de enum e antes das tags do campo.
+type SchemaTransformer interface {
+	TransformSchema(s *Schema)
+}
+
+var (
+	schemaProviderType    = reflect.TypeFor[SchemaProvider]()
+	schemaTransformerType = reflect.TypeFor[SchemaTransformer]()
+)
+
+// valueOf devolve um valor zero de t (ou um *t, se o método for de ponteiro)
+// que implementa iface.
+func valueOf(t, iface reflect.Type) (any, bool) {
+	switch {
+	case t.Kind() == reflect.Interface:
+		return nil, false
+	case t.Implements(iface):
+		return reflect.Zero(t).Interface(), true
+	case reflect.PointerTo(t).Implements(iface):
+		return reflect.New(t).Interface(), true
+	}
+	return nil, false
+}
+
+// customize aplica a s, gerado para t, o enum e o TransformSchema do tipo.
+func customize(t reflect.Type, s *Schema) {
+	if values := enumValues(t); values != nil {
+		applyEnum(s, values)
+	}
+	if v, ok := valueOf(t, schemaTransformerType); ok {
+		v.(SchemaTransformer).TransformSchema(s)
+	}
+}
+
+// ---------------- Enums -----------------
+
+// EnumProvider é implementado por tipos com conjunto fixo de valores:
+//
+//	func (Status) OASEnum() []any { return []any{StatusActive, StatusDisabled} }
+//
+// Cada valor pode ser um EnumValue, para dar nome e descrição a ele.
+type EnumProvider interface {
+	OASEnum() []any
+}
+
+// EnumValue é um valor de enum com nome (x-enum-varnames) e descrição
+// (x-enum-descriptions) opcionais.
+type EnumValue struct {
+	Value       any
+	Name        string
+	Description string
+}
+
+var enumProviderType = reflect.TypeFor[EnumProvider]()
+
+var enums = struct {
+	sync.RWMutex
+	values map[reflect.Type][]any
+}{values: map[reflect.Type][]any{}}
+
+// RegisterEnum registra (ou substitui) os valores do tipo T, cada um um T ou
+// um EnumValue; sem valores, remove o registro. Vale sobre OASEnum:
+// RegisterEnum[Status](StatusActive, StatusDisabled).
+func RegisterEnum[T any](values ...any) {
+	enums.Lock()
+	defer enums.Unlock()
+	t := reflect.TypeFor[T]()
+	if len(values) == 0 {
+		delete(enums.values, t)
+		return
+	}
+	enums.values[t] = values
+}
+
+// enumValues devolve os valores registrados para t ou os de OASEnum.
+func enumValues(t reflect.Type) []any {
+	enums.RLock()
+	values, ok := enums.values[t]
+	enums.RUnlock()
+	if ok {
+		return values
+	}
+	if v, ok := valueOf(t, enumProviderType); ok {
+		return v.(EnumProvider).OASEnum()
+	}
+	return nil
+}
+
+// applyEnum preenche Enum de s e, se algum valor tiver nome ou descrição,
+// as extensões x-enum-varnames e x-enum-descriptions.
+func applyEnum(s *Schema, values []any) {
+	names := make([]string, len(values))
+	descriptions := make([]string, len(values))
+	named, described := false, false
+	for i, v := range values {
+		if ev, ok := v.(EnumValue); ok {
+			v, names[i], descriptions[i] = ev.Value, ev.Name, ev.Description
+			named = named || ev.Name != ""
+			described = described || ev.Description != ""
+		}
+		s.Enum = append(s.Enum, plainValue(v))
+	}
+	if named {
+		s.Extensions = s.Extensions.With("x-enum-varnames", names)
+	}
+	if described {
+		s.Extensions = s.Extensions.With("x-enum-descriptions", descriptions)
+	}
+}
+
+// plainValue converte valores de tipos nomeados (Status("active")) para o
+// tipo básico (string, int64, uint64, float64 ou bool); tipos com
+// serialização própria passam por ela.
+func plainValue(v any) any {
+	switch v.(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return normalizeJSON(v)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Bool:
+		return rv.Bool()
+	}
+	return v
+}
+
+// ---------------- Campos de structs -----------------
+
+// structField é um campo serializado de um struct, já com o nome JSON.
+type structField struct {
+	name     string
+	goName   string
+	tag      reflect.StructTag
+	typ      reflect.Type
+	optional bool // omitempty ou omitzero
+	asString bool // opção ",string"
+	tagged   bool
+	depth    int
+}
+
+// structFields lista os campos que encoding/json serializa em t, na mesma
+// ordem, promovendo os de structs embutidas: entre campos de mesmo nome vence
+// o mais raso e, na mesma profundidade, o único com tag; empates somem.
+func structFields(t reflect.Type) []structField {
+	var all []structField
+	// viaPointer: campos de um *struct embutido somem quando ele é nil
+	var walk func(t reflect.Type, depth int, viaPointer bool, visited map[reflect.Type]bool)
+	walk = func(t reflect.Type, depth int, viaPointer bool, visited map[reflect.Type]bool) {
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		defer delete(visited, t)
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			ft := sf.Type
+			if sf.Anonymous {
+				et := ft
+				if et.Kind() == reflect.Pointer {
+					et = et.Elem()
+				}
+				if name == "" && et.Kind() == reflect.Struct {
+					walk(et, depth+1, viaPointer || ft.Kind() == reflect.Pointer, visited)
+					continue
+				}
+				if !sf.IsExported() && et.Kind() != reflect.Struct {
+					continue
+				}
+			} else if !sf.IsExported() {
+				continue
+			}
+			f := structField{name: name, goName: sf.Name, tag: sf.Tag, typ: ft, optional: viaPointer, tagged: name != "", depth: depth}
+			if f.name == "" {
+				f.name = sf.Name
+			}
+			for _, opt := range strings.Split(opts, ",") {
+				switch opt {
+				case "omitempty", "omitzero":
+					f.optional = true
+				case "string":
+					f.asString = quotable(ft)
+				}
+			}
+			all = append(all, f)
+		}
+	}
+	walk(t, 0, false, map[reflect.Type]bool{})
+
+	byName := map[string][]int{}
+	for i, f := range all {
+		byName[f.name] = append(byName[f.name], i)
+	}
+	var fields []structField
+	for i, f := range all {
+		if dominant(all, byName[f.name]) == i {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// dominant devolve o índice, entre candidates, do campo que encoding/json
+// serializa, ou -1 se houver empate.
+func dominant(all []structField, candidates []int) int {
+	best := -1
+	tie := false
+	for _, i := range candidates {
+		switch {
+		case best == -1 || all[i].depth < all[best].depth:
+			best, tie = i, false
+		case all[i].depth > all[best].depth:
+		case all[i].tagged && !all[best].tagged:
+			best, tie = i, false
+		case all[i].tagged == all[best].tagged:
+			tie = true
+		}
+	}
+	if tie {
+		return -1
+	}
+	return best
+}
+
+// quotable informa se a opção ",string" vale para t: só escalares (ou
+// ponteiros para eles) são serializados entre aspas.
+func quotable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// ---------------- Tags oas/jsonschema -----------------
+
+// applyTags aplica a s as tags oas e jsonschema do campo, no formato
+// oas:"description=Nome do usuário,minLength=1,example=Ana,readOnly". Chaves:
+// title, description, format, pattern, minimum, maximum, minLength,
+// maxLength, default, example, enum (repetida: enum=a,enum=b) e as flags
+// deprecated, readOnly e writeOnly (sozinhas valem true). Vírgulas dentro de
+// um valor são escapadas (description=a\\,b na tag). Valores de default,
+// example e enum seguem o tipo do campo; em arrays e objetos, são JSON.
+//
+// A tag oas é estrita (chave desconhecida é erro); jsonschema é compartilhada
+// com outras bibliotecas e suas chaves desconhecidas são ignoradas.
+func applyTags(fs *SchemaOrRef, tag reflect.StructTag) error {
+	for _, name := range []string{"jsonschema", "oas"} {
+		value, ok := tag.Lookup(name)
+		if !ok || value == "" {
+			continue
+		}
+		if fs.Schema == nil {
+			// $ref: as tags viram keywords irmãos
+			fs.Schema = &Schema{}
+		}
+		for _, item := range splitTag(value) {
+			key, raw, _ := strings.Cut(item, "=")
+			err := applyTag(fs.Schema, key, raw)
+			if errors.Is(err, errUnknownTag) && name == "jsonschema" {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("tag %s %q: %w", name, item, err)
+			}
+		}
+	}
+	return nil
+}
+
+var errUnknownTag = errors.New("chave desconhecida")
+
+func applyTag(s *Schema, key, raw string) error {
+	var err error
+	switch key {
+	case "title":
+		s.Title = Ptr(raw)
+	case "description":
+		s.Description = Ptr(raw)
+	case "format":
+		s.Format = Ptr(raw)
+	case "pattern":
+		// regex ECMA-262 que o RE2 não suporta também vale
+		var broken bool
+		if broken, err = regexError(raw); !broken {
+			s.Pattern, err = Ptr(raw), nil
+		}
+	case "minimum", "maximum":
+		var n float64
+		if n, err = strconv.ParseFloat(raw, 64); err == nil {
+			if key == "minimum" {
+				s.Minimum = &n
+			} else {
+				s.Maximum = &n
+			}
+		}
+	case "minLength", "maxLength":
+		var n int
+		if n, err = strconv.Atoi(raw); err == nil {
+			if key == "minLength" {
+				s.MinLength = &n
+			} else {
+				s.MaxLength = &n
+			}
+		}
+	case "deprecated", "readOnly", "writeOnly":
+		b := true
+		if raw != "" {
+			b, err = strconv.ParseBool(raw)
+		}
+		switch key {
+		case "deprecated":
+			s.Deprecated = &b
+		case "readOnly":
+			s.ReadOnly = &b
+		default:
+			s.WriteOnly = &b
+		}
+	case "default", "example", "enum":
+		var v any
+		if v, err = tagValue(s, raw); err == nil {
+			switch key {
+			case "default":
+				s.Default = v
+			case "example":
+				s.Example = v
+			default:
+				s.Enum = append(s.Enum, v)
+			}
+		}
+	default:
+		return errUnknownTag
+	}
+	return err
+}
+
+// tagValue converte raw para o tipo de s.
+func tagValue(s *Schema, raw string) (any, error) {
+	switch primaryType(s) {
+	case "string":
+		return raw, nil
+	case "integer":
+		return strconv.ParseInt(raw, 10, 64)
+	case "number":
+		return strconv.ParseFloat(raw, 64)
+	case "boolean":
+		return strconv.ParseBool(raw)
+	}
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return raw, nil
+	}
+	return v, nil
+}
+
+// primaryType devolve o primeiro tipo de s que não é null.
+func primaryType(s *Schema) string {
+	switch {
+	case s.Type == nil:
+		return ""
+	case s.Type.One != nil:
+		return *s.Type.One
+	}
+	for _, t := range s.Type.Many {
+		if t != "null" {
+			return t
+		}
+	}
+	return ""
+}
+
+// splitTag separa value nas vírgulas que não são "\,".
+func splitTag(value string) []string {
+	var parts []string
+	var cur strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == ',':
+			cur.WriteByte(',')
+			i++
+		case value[i] == ',':
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(value[i])
+		}
+	}
+	return append(parts, cur.String())
+}
+
+// ---------------- Tags validate (go-playground/validator) -----------------
+
+// Formatos e padrões equivalentes às regras do validator.
+var (
+	validateFormats = map[string]string{
+		"email": "email", "url": "uri", "uri": "uri", "http_url": "uri",
+		"uuid": "uuid", "uuid4": "uuid", "uuid_rfc4122": "uuid", "uuid4_rfc4122": "uuid",
+		"ipv4": "ipv4", "ip4_addr": "ipv4", "ipv6": "ipv6", "ip6_addr": "ipv6",
+		"hostname": "hostname", "hostname_rfc1123": "hostname", "fqdn": "hostname",
+		"base64": "byte",
+	}
+	validatePatterns = map[string]string{
+		"alpha":       "^[a-zA-Z]+$",
+		"alphanum":    "^[a-zA-Z0-9]+$",
+		"numeric":     "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+		"number":      "^[0-9]+$",
+		"hexadecimal": "^(0[xX])?[0-9a-fA-F]+$",
+		"e164":        "^\\+[1-9]?[0-9]{7,14}$",
+	}
+	reOneOf = regexp.MustCompile(`'[^']*'|\S+`)
+)
+
+// applyValidate traduz a tag validate de um campo do tipo t em restrições de
+// fs e informa se ela tem required:
+//
+//   - required torna o campo obrigatório (e ponteiros deixam de aceitar null);
+//   - min, max, len, gt, gte, lt e lte limitam o tamanho de strings
+//     (minLength/maxLength), o valor de números (minimum/maximum e
+//     exclusive*), os itens de slices (minItems/maxItems) e as chaves de mapas
+//     (minProperties/maxProperties);
+//   - email, url, uuid, ipv4, hostname, ... viram format e alpha, alphanum,
+//     numeric, ... viram pattern;
+//   - oneof vira enum e unique, uniqueItems;
+//   - dive aplica as regras seguintes aos itens (ou valores do mapa); em
+//     mapas, dive,keys,...,endkeys aplica as regras entre keys e endkeys às
+//     chaves (propertyNames).
+//
+// Regras sem equivalente (ex.: eqfield, alternativas com "|", limites e dive
+// em []byte, que é string base64) são ignoradas.
+func applyValidate(fs *SchemaOrRef, t reflect.Type, tag string) (bool, error) {
+	if tag == "" || tag == "-" {
+		return false, nil
+	}
+	return validateRules(fs, t, strings.Split(tag, ","))
+}
+
+func validateRules(fs *SchemaOrRef, t reflect.Type, rules []string) (bool, error) {
+	pointer := t.Kind() == reflect.Pointer
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	required := false
+	for i := 0; i < len(rules); i++ {
+		if strings.Contains(rules[i], "|") {
+			continue
+		}
+		rule, param, _ := strings.Cut(rules[i], "=")
+		if fs.Schema == nil {
+			// $ref: as restrições viram keywords irmãos
+			fs.Schema = &Schema{}
+		}
+		s := fs.Schema
+		switch {
+		case rule == "required":
+			required = true
+			if pointer {
+				*fs = nonNullable(*fs)
+			}
+		case rule == "dive":
+			elem := diveTarget(s, t)
+			if elem == nil {
+				// sem itens no schema (ex.: []byte é string): nada a aplicar
+				return required, nil
+			}
+			rest := rules[i+1:]
+			if t.Kind() == reflect.Map && len(rest) > 0 && rest[0] == "keys" {
+				end := 1
+				for end < len(rest) && rest[end] != "endkeys" {
+					end++
+				}
+				if s.PropertyNames == nil {
+					s.PropertyNames = &SchemaOrRef{Schema: typed("string")}
+				}
+				if _, err := validateRules(s.PropertyNames, t.Key(), rest[1:end]); err != nil {
+					return required, err
+				}
+				rest = rest[min(end+1, len(rest)):]
+			}
+			_, err := validateRules(elem, t.Elem(), rest)
+			return required, err
+		case rule == "unique":
+			if primaryType(s) == "array" {
+				s.UniqueItems = Ptr(true)
+			}
+		case rule == "oneof":
+			for _, v := range reOneOf.FindAllString(param, -1) {
+				value, err := tagValue(s, strings.Trim(v, "'"))
+				if err != nil {
+					return required, fmt.Errorf("validate %q: %w", rules[i], err)
+				}
+				s.Enum = append(s.Enum, value)
+			}
+		case validateFormats[rule] != "":
+			s.Format = Ptr(validateFormats[rule])
+		case validatePatterns[rule] != "":
+			s.Pattern = Ptr(validatePatterns[rule])
+		case rule == "min" || rule == "max" || rule == "len" || rule == "gt" || rule == "gte" || rule == "lt" || rule == "lte":
+			if err := validateBound(s, t, rule, param); err != nil {
+				return required, fmt.Errorf("validate %q: %w", rules[i], err)
+			}
+		}
+	}
+	return required, nil
+}
+
+// validateBound aplica um limite (min, max, len, gt, gte, lt, lte) conforme
+// o tipo do campo. O limite é ignorado se o JSON do campo não tem a forma do
+// tipo Go (ex.: []byte é string base64, o número com ",string" é string).
+func validateBound(s *Schema, t reflect.Type, rule, param string) error {
+	kind := primaryType(s)
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if kind != "integer" && kind != "number" {
+			return nil
+		}
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return err
+		}
+		switch rule {
+		case "min", "gte":
+			s.Minimum = &n
+		case "max", "lte":
+			s.Maximum = &n
+		case "len":
+			s.Minimum, s.Maximum = &n, Ptr(n)
+		case "gt":
+			s.ExclusiveMinimum = &n
+		case "lt":
+			s.ExclusiveMaximum = &n
+		}
+		return nil
+	}
+	var minField, maxField **int
+	switch {
+	case t.Kind() == reflect.String && kind == "string":
+		minField, maxField = &s.MinLength, &s.MaxLength
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && kind == "array":
+		minField, maxField = &s.MinItems, &s.MaxItems
+	case t.Kind() == reflect.Map && kind == "object":
+		minField, maxField = &s.MinProperties, &s.MaxProperties
+	default:
+		return nil
+	}
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return err
+	}
+	// tamanho negativo (ex.: lt=0) não é um schema válido: a regra é ignorada
+	set := func(field **int, n int) {
+		if n >= 0 {
+			*field = Ptr(n)
+		}
+	}
+	switch rule {
+	case "min", "gte":
+		set(minField, n)
+	case "max", "lte":
+		set(maxField, n)
+	case "len":
+		set(minField, n)
+		set(maxField, n)
+	case "gt":
+		set(minField, n+1)
+	case "lt":
+		set(maxField, n-1)
+	}
+	return nil
+}
+
+// diveTarget devolve o schema dos itens (ou dos valores do mapa) de s.
+func diveTarget(s *Schema, t reflect.Type) *SchemaOrRef {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if s.Items != nil {
+			return s.Items.Single
+		}
+	case reflect.Map:
+		if s.AdditionalProperties != nil {
+			return s.AdditionalProperties.Schema
+		}
+	}
+	return nil
+}
+
+// nonNullable desfaz nullable: tira null de type (e de enum) ou o ramo null
+// de anyOf.
+func nonNullable(s SchemaOrRef) SchemaOrRef {
+	switch {
+	case s.Schema == nil:
+		return s
+	case len(s.Schema.AnyOf) == 2 && s.Schema.AnyOf[0].Ref != nil:
+		s.Schema.AnyOf = s.Schema.AnyOf[:1]
+	case s.Schema.Type != nil && len(s.Schema.Type.Many) > 0:
+		var types []string
+		for _, t := range s.Schema.Type.Many {
+			if t != "null" {
+				types = append(types, t)
+			}
+		}
+		if len(types) == 1 {
+			s.Schema.Type = &StringOrArray{One: &types[0]}
+		} else {
+			s.Schema.Type = &StringOrArray{Many: types}
+		}
+		if s.Schema.Enum != nil {
+			var values Enum
+			for _, v := range s.Schema.Enum {
+				if v != nil {
+					values = append(values, v)
+				}
+			}
+			s.Schema.Enum = values
+		}
+	}
+	return s
+}
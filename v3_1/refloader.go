@@ -0,0 +1,137 @@
+package oas
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ========== Loaders de $ref externos ==========
+
+// RefLoader busca o conteúdo (JSON ou YAML) de um documento referenciado por
+// um $ref externo. A uri já vem resolvida contra a base do documento raiz.
+type RefLoader interface {
+	Load(ctx context.Context, uri string) ([]byte, error)
+}
+
+// RefLoaderFunc adapta uma função ao RefLoader.
+type RefLoaderFunc func(ctx context.Context, uri string) ([]byte, error)
+
+func (f RefLoaderFunc) Load(ctx context.Context, uri string) ([]byte, error) {
+	return f(ctx, uri)
+}
+
+// FileLoader lê do sistema de arquivos. Caminhos relativos partem de Dir
+// (ou do diretório corrente, se vazio); aceita também URIs "file://".
+type FileLoader struct {
+	Dir string
+}
+
+func (l FileLoader) Load(ctx context.Context, uri string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p := uri
+	if strings.HasPrefix(uri, "file:") {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, err
+		}
+		p = u.Path
+	} else if u, err := url.Parse(uri); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		return nil, fmt.Errorf("FileLoader: esquema %q não suportado", u.Scheme)
+	}
+	p = filepath.FromSlash(p)
+	if !filepath.IsAbs(p) && l.Dir != "" {
+		p = filepath.Join(l.Dir, p)
+	}
+	return os.ReadFile(p)
+}
+
+// FSLoader lê de um fs.FS (ex.: embed.FS); a uri é um caminho dentro dele.
+type FSLoader struct {
+	FS fs.FS
+}
+
+func (l FSLoader) Load(ctx context.Context, uri string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(uri); err != nil || u.Scheme != "" {
+		return nil, fmt.Errorf("FSLoader: uri %q não é um caminho", uri)
+	}
+	return fs.ReadFile(l.FS, strings.TrimPrefix(path.Clean("/"+uri), "/"))
+}
+
+// MapLoader serve documentos em memória, indexados pela uri (ou caminho).
+type MapLoader map[string][]byte
+
+func (l MapLoader) Load(ctx context.Context, uri string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if b, ok := l[uri]; ok {
+		return b, nil
+	}
+	if b, ok := l[path.Clean(uri)]; ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("MapLoader: %q: %w", uri, fs.ErrNotExist)
+}
+
+// DefaultHTTPMaxBytes é o tamanho máximo padrão de um documento lido por
+// HTTPLoader (10 MiB).
+const DefaultHTTPMaxBytes = 10 << 20
+
+// HTTPLoader busca documentos via HTTP(S). Client nil usa http.DefaultClient;
+// MaxBytes limita o tamanho do corpo (0 usa DefaultHTTPMaxBytes) e um corpo
+// maior é erro.
+type HTTPLoader struct {
+	Client   *http.Client
+	MaxBytes int64
+}
+
+func (l HTTPLoader) Load(ctx context.Context, uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("HTTPLoader: esquema %q não suportado", u.Scheme)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("HTTPLoader: GET %s: status %d", uri, resp.StatusCode)
+	}
+	limit := l.MaxBytes
+	if limit <= 0 {
+		limit = DefaultHTTPMaxBytes
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("HTTPLoader: GET %s: documento maior que %d bytes", uri, limit)
+	}
+	return data, nil
+}
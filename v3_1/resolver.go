@@ -0,0 +1,623 @@
+package oas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ========== Resolução de $ref ==========
+
+var (
+	// ErrRefNotFound indica um $ref cujo alvo não existe no documento.
+	ErrRefNotFound = errors.New("alvo não encontrado")
+	// ErrRefCycle indica uma cadeia de $ref que volta para si mesma.
+	ErrRefCycle = errors.New("referência circular")
+	// ErrExternalRef indica um $ref para outro documento sem RefLoader configurado.
+	ErrExternalRef = errors.New("referência externa sem RefLoader")
+	// ErrInvalidPointer indica um fragmento que não é um JSON Pointer válido.
+	ErrInvalidPointer = errors.New("JSON Pointer inválido")
+)
+
+// RefError descreve uma falha ao seguir um $ref.
+type RefError struct {
+	Ref      string
+	Err      error
+	Pointer  string    // onde o $ref está, quando conhecido (Bundle, Dereference)
+	Position *Position // posição do $ref na origem, se o documento tem source map
+}
+
+func (e *RefError) Error() string {
+	if e.Position != nil {
+		return fmt.Sprintf("%s: $ref %q: %v", e.Position, e.Ref, e.Err)
+	}
+	return fmt.Sprintf("$ref %q: %v", e.Ref, e.Err)
+}
+
+func (e *RefError) Unwrap() error {
+	return e.Err
+}
+
+// refable é implementado por todos os tipos "OrRef".
+type refable interface {
+	reference() *Reference
+}
+
+func (s SchemaOrRef) reference() *Reference         { return s.Ref }
+func (p PathItemOrRef) reference() *Reference       { return p.Ref }
+func (p ParameterOrRef) reference() *Reference      { return p.Ref }
+func (r RequestBodyOrRef) reference() *Reference    { return r.Ref }
+func (r ResponseOrRef) reference() *Reference       { return r.Ref }
+func (h HeaderOrRef) reference() *Reference         { return h.Ref }
+func (e ExampleOrRef) reference() *Reference        { return e.Ref }
+func (l LinkOrRef) reference() *Reference           { return l.Ref }
+func (c CallbackOrRef) reference() *Reference       { return c.Ref }
+func (s SecuritySchemeOrRef) reference() *Reference { return s.Ref }
+
+// overrider é implementado pelos "OrRef" cujo alvo aceita o summary e/ou a
+// description de um Reference.
+type overrider interface {
+	override(ref *Reference)
+}
+
+func (p *PathItemOrRef) override(ref *Reference) {
+	if p.PathItem != nil {
+		overrideText(&p.PathItem.Summary, ref.Summary)
+		overrideText(&p.PathItem.Description, ref.Description)
+	}
+}
+
+func (p *ParameterOrRef) override(ref *Reference) {
+	if p.Param != nil {
+		overrideText(&p.Param.Description, ref.Description)
+	}
+}
+
+func (r *RequestBodyOrRef) override(ref *Reference) {
+	if r.Body != nil {
+		overrideText(&r.Body.Description, ref.Description)
+	}
+}
+
+func (r *ResponseOrRef) override(ref *Reference) {
+	if r.Resp != nil && ref.Description != nil {
+		r.Resp.Description = *ref.Description
+	}
+}
+
+func (h *HeaderOrRef) override(ref *Reference) {
+	if h.Header != nil {
+		overrideText(&h.Header.Description, ref.Description)
+	}
+}
+
+func (e *ExampleOrRef) override(ref *Reference) {
+	if e.Example != nil {
+		overrideText(&e.Example.Summary, ref.Summary)
+		overrideText(&e.Example.Description, ref.Description)
+	}
+}
+
+func (l *LinkOrRef) override(ref *Reference) {
+	if l.Link != nil {
+		overrideText(&l.Link.Description, ref.Description)
+	}
+}
+
+func (s *SecuritySchemeOrRef) override(ref *Reference) {
+	if s.Scheme != nil {
+		overrideText(&s.Scheme.Description, ref.Description)
+	}
+}
+
+func overrideText(field **string, value *string) {
+	if value != nil {
+		*field = value
+	}
+}
+
+// applyOverrides aplica ao valor resolvido os summary/description dos $ref
+// seguidos até ele; o $ref mais externo prevalece.
+func applyOverrides(site any, refs []*Reference) {
+	o, ok := site.(overrider)
+	if !ok {
+		return
+	}
+	for i := len(refs) - 1; i >= 0; i-- {
+		o.override(refs[i])
+	}
+}
+
+// Resolver segue $ref de um Document: internos (JSON Pointer, ex.:
+// "#/components/schemas/User") e, com um RefLoader configurado, externos
+// (ex.: "./schemas/user.yaml#/User"). Ele trabalha sobre um retrato do
+// documento tirado em NewResolver; alterações posteriores não são vistas.
+//
+// Os $ref aninhados em documentos externos são reescritos relativos ao
+// documento raiz, de modo que qualquer valor devolvido pode ser resolvido
+// novamente pelo mesmo Resolver.
+type Resolver struct {
+	root   any
+	err    error
+	base   *url.URL
+	loader RefLoader
+	ctx    context.Context
+
+	ids  map[string]any // $id absoluto -> schema embutido no documento raiz
+	mu   sync.Mutex
+	docs map[string]any
+}
+
+type ResolverOption func(*Resolver)
+
+// WithRefLoader habilita $ref externos, buscados pelo loader informado.
+func WithRefLoader(loader RefLoader) ResolverOption {
+	return func(r *Resolver) { r.loader = loader }
+}
+
+// WithBaseURI define a URI (caminho ou URL) do documento raiz, base para os
+// $ref relativos.
+func WithBaseURI(uri string) ResolverOption {
+	return func(r *Resolver) {
+		u, err := url.Parse(filepath.ToSlash(uri))
+		if err != nil {
+			r.err = err
+			return
+		}
+		u.Fragment, u.RawFragment = "", ""
+		r.base = u
+	}
+}
+
+// WithContext define o contexto repassado ao RefLoader.
+func WithContext(ctx context.Context) ResolverOption {
+	return func(r *Resolver) { r.ctx = ctx }
+}
+
+func NewResolver(doc *Document, opts ...ResolverOption) *Resolver {
+	r := &Resolver{base: &url.URL{}, ctx: context.Background(), docs: map[string]any{}}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.err != nil {
+		return r
+	}
+	b, err := json.Marshal(doc)
+	if err == nil {
+		err = json.Unmarshal(b, &r.root)
+	}
+	r.err = err
+	r.ids = schemaIDs(r.root)
+	return r
+}
+
+// schemaIDs indexa os objetos do documento com $id absoluto: schemas
+// embutidos (como os de SchemaOf para tipos recursivos) que são alvo de $ref
+// pela própria URI.
+func schemaIDs(root any) map[string]any {
+	ids := map[string]any{}
+	walkObjects(root, "", func(obj map[string]any) {
+		id, ok := obj["$id"].(string)
+		if !ok {
+			return
+		}
+		if u, err := url.Parse(id); err == nil && u.IsAbs() {
+			u.Fragment, u.RawFragment = "", ""
+			ids[u.String()] = obj
+		}
+	})
+	return ids
+}
+
+// embedded devolve o schema do documento raiz cujo $id é target.
+func (r *Resolver) embedded(target *url.URL) (any, bool) {
+	node, ok := r.ids[target.String()]
+	return node, ok
+}
+
+// ResolveSchema segue os $ref de s. Schemas booleanos viram o Schema
+// equivalente (true: {}; false: {"not": {}}). Keywords ao lado de um $ref são
+// mantidos, com o alvo em allOf: {"$ref": X, "description": D} resolve para
+// {"description": D, "allOf": [X]}.
+func (r *Resolver) ResolveSchema(s SchemaOrRef) (*Schema, error) {
+	var siblings []*Schema
+	seen := map[string]bool{}
+	for s.Ref != nil {
+		if s.Schema != nil {
+			siblings = append(siblings, s.Schema)
+		}
+		var next SchemaOrRef
+		if err := r.follow(s.Ref.Ref, seen, &next); err != nil {
+			return nil, err
+		}
+		s = next
+	}
+	out := s.Schema
+	if s.Bool != nil {
+		out = boolSchema(*s.Bool)
+	}
+	for i := len(siblings) - 1; i >= 0; i-- {
+		merged := *siblings[i]
+		merged.AllOf = append(AllOf{{Schema: out}}, merged.AllOf...)
+		out = &merged
+	}
+	return out, nil
+}
+
+// boolSchema devolve o Schema equivalente ao schema booleano b.
+func boolSchema(b bool) *Schema {
+	if b {
+		return &Schema{}
+	}
+	return &Schema{Not: Not{{Schema: &Schema{}}}}
+}
+
+func (r *Resolver) ResolvePathItem(p PathItemOrRef) (*PathItem, error) {
+	v, err := resolveRef(r, p)
+	return v.PathItem, err
+}
+
+func (r *Resolver) ResolveParameter(p ParameterOrRef) (*Parameter, error) {
+	v, err := resolveRef(r, p)
+	return v.Param, err
+}
+
+func (r *Resolver) ResolveRequestBody(rb RequestBodyOrRef) (*RequestBody, error) {
+	v, err := resolveRef(r, rb)
+	return v.Body, err
+}
+
+func (r *Resolver) ResolveResponse(rr ResponseOrRef) (*Response, error) {
+	v, err := resolveRef(r, rr)
+	return v.Resp, err
+}
+
+func (r *Resolver) ResolveHeader(h HeaderOrRef) (*Header, error) {
+	v, err := resolveRef(r, h)
+	return v.Header, err
+}
+
+func (r *Resolver) ResolveExample(e ExampleOrRef) (*Example, error) {
+	v, err := resolveRef(r, e)
+	return v.Example, err
+}
+
+func (r *Resolver) ResolveLink(l LinkOrRef) (*Link, error) {
+	v, err := resolveRef(r, l)
+	return v.Link, err
+}
+
+func (r *Resolver) ResolveCallback(c CallbackOrRef) (*Callback, error) {
+	v, err := resolveRef(r, c)
+	return v.Callback, err
+}
+
+func (r *Resolver) ResolveSecurityScheme(s SecuritySchemeOrRef) (*SecurityScheme, error) {
+	v, err := resolveRef(r, s)
+	return v.Scheme, err
+}
+
+// resolveRef segue a cadeia de $ref até um valor concreto do mesmo tipo.
+func resolveRef[T refable](r *Resolver, v T) (T, error) {
+	seen := map[string]bool{}
+	var refs []*Reference
+	for ref := v.reference(); ref != nil; ref = v.reference() {
+		var next T
+		if err := r.follow(ref.Ref, seen, &next); err != nil {
+			return v, err
+		}
+		refs = append(refs, ref)
+		v = next
+	}
+	applyOverrides(&v, refs)
+	return v, nil
+}
+
+// follow decodifica em out o alvo de ref; seen guarda os alvos já visitados
+// na cadeia, contra ciclos.
+func (r *Resolver) follow(ref string, seen map[string]bool, out any) error {
+	node, key, err := r.lookup(ref)
+	if err == nil && seen[key] {
+		err = ErrRefCycle
+	}
+	if err == nil {
+		seen[key] = true
+		err = decodeNode(node, out)
+	}
+	if err != nil {
+		return &RefError{Ref: ref, Err: err}
+	}
+	return nil
+}
+
+// lookup devolve o nó apontado por um $ref (relativo ao documento raiz) e
+// uma chave canônica do alvo, usada na detecção de ciclos.
+func (r *Resolver) lookup(ref string) (any, string, error) {
+	if r.err != nil {
+		return nil, "", r.err
+	}
+	target, fragment, err := r.target(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	key := target.String() + "#" + fragment
+	root := r.root
+	if node, ok := r.embedded(target); ok {
+		root = node
+	} else if !sameDocument(target, r.base) {
+		if root, err = r.external(target); err != nil {
+			return nil, "", err
+		}
+	}
+	node, err := pointerGet(root, fragment)
+	return node, key, err
+}
+
+// target separa um $ref em URI absoluta do documento e fragmento decodificado.
+func (r *Resolver) target(ref string) (*url.URL, string, error) {
+	docPart, rawFragment, _ := strings.Cut(ref, "#")
+	fragment, err := url.PathUnescape(rawFragment)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidPointer, err)
+	}
+	if docPart == "" {
+		return r.base, fragment, nil
+	}
+	u, err := url.Parse(docPart)
+	if err != nil {
+		return nil, "", err
+	}
+	return resolveURI(r.base, u), fragment, nil
+}
+
+// external carrega (uma única vez) um documento externo pelo RefLoader.
+func (r *Resolver) external(u *url.URL) (any, error) {
+	if r.loader == nil {
+		return nil, ErrExternalRef
+	}
+	uri := u.String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if node, ok := r.docs[uri]; ok {
+		return node, nil
+	}
+	data, err := r.loader.Load(r.ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	data = trimBOM(data)
+	raw := data
+	if DetectFormat(u.Path, data) == FormatYAML {
+		if raw, err = yamlToJSON(data); err != nil {
+			return nil, err
+		}
+	}
+	var node any
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return nil, err
+	}
+	r.rebaseRefs(node, u)
+	r.docs[uri] = node
+	return node, nil
+}
+
+// rebaseRefs reescreve os $ref de um documento externo para que fiquem
+// relativos ao documento raiz em vez do próprio arquivo.
+func (r *Resolver) rebaseRefs(node any, docURI *url.URL) {
+	walkRefObjects(node, func(obj map[string]any) {
+		ref := obj["$ref"].(string)
+		docPart, fragment, hasFragment := strings.Cut(ref, "#")
+		target := docURI
+		if docPart != "" {
+			u, err := url.Parse(docPart)
+			if err != nil {
+				return
+			}
+			target = resolveURI(docURI, u)
+		}
+		rel := relativeURI(r.base, target)
+		if hasFragment {
+			rel += "#" + fragment
+		}
+		obj["$ref"] = rel
+	})
+}
+
+// walkRefObjects visita todo objeto com "$ref" string, sem descer em valores
+// literais (exemplos, defaults, enums) nem em extensões.
+func walkRefObjects(node any, fn func(map[string]any)) {
+	walkObjects(node, "", func(obj map[string]any) {
+		if _, ok := obj["$ref"].(string); ok {
+			fn(obj)
+		}
+	})
+}
+
+// namedMaps são chaves cujos filhos são nomes livres (ex.: uma propriedade
+// chamada "default"), e não palavras-chave.
+var namedMaps = map[string]bool{
+	"properties": true, "patternProperties": true, "$defs": true, "dependentSchemas": true,
+	"schemas": true, "responses": true, "parameters": true, "examples": true,
+	"requestBodies": true, "headers": true, "securitySchemes": true, "links": true,
+	"callbacks": true, "pathItems": true, "content": true, "encoding": true,
+	"paths": true, "webhooks": true,
+}
+
+// walkObjects visita todo objeto de node, com as mesmas exceções de
+// walkRefObjects; parent é a chave sob a qual node está.
+func walkObjects(node any, parent string, fn func(map[string]any)) {
+	switch n := node.(type) {
+	case map[string]any:
+		fn(n)
+		for k, v := range n {
+			if !namedMaps[parent] {
+				switch k {
+				case "example", "default", "const", "enum", "value":
+					continue
+				}
+				if strings.HasPrefix(k, "x-") {
+					continue
+				}
+			}
+			walkObjects(v, k, fn)
+		}
+	case []any:
+		for _, v := range n {
+			walkObjects(v, "", fn)
+		}
+	}
+}
+
+// ========== URIs ==========
+
+// resolveURI resolve ref contra base. Caminhos sem esquema continuam
+// relativos (ao diretório corrente ou à raiz do fs.FS do loader).
+func resolveURI(base, ref *url.URL) *url.URL {
+	if ref.IsAbs() || base.IsAbs() {
+		return base.ResolveReference(ref)
+	}
+	out := *ref
+	out.Fragment, out.RawFragment = "", ""
+	switch {
+	case ref.Path == "":
+		out.Path = base.Path
+	case !path.IsAbs(ref.Path):
+		out.Path = path.Join(path.Dir(base.Path), ref.Path)
+	default:
+		out.Path = path.Clean(ref.Path)
+	}
+	return &out
+}
+
+func sameDocument(a, b *url.URL) bool {
+	ca, cb := *a, *b
+	ca.Fragment, ca.RawFragment, cb.Fragment, cb.RawFragment = "", "", "", ""
+	if !ca.IsAbs() {
+		ca.Path = path.Clean("/" + ca.Path)
+	}
+	if !cb.IsAbs() {
+		cb.Path = path.Clean("/" + cb.Path)
+	}
+	return ca.String() == cb.String()
+}
+
+// relativeURI expressa target relativo a base (sem fragmento), quando possível.
+func relativeURI(base, target *url.URL) string {
+	if sameDocument(base, target) {
+		return ""
+	}
+	if target.IsAbs() || base.IsAbs() {
+		return target.String()
+	}
+	if path.IsAbs(target.Path) != path.IsAbs(base.Path) {
+		return target.Path
+	}
+	from := splitPath(path.Dir(base.Path))
+	to := splitPath(path.Clean(target.Path))
+	i := 0
+	for i < len(from) && i < len(to) && from[i] == to[i] {
+		i++
+	}
+	parts := make([]string, 0, len(from)-i+len(to)-i)
+	for _, p := range from[i:] {
+		if p == ".." {
+			// a base sobe acima da origem: não há caminho relativo confiável
+			return target.Path
+		}
+		parts = append(parts, "..")
+	}
+	parts = append(parts, to[i:]...)
+	return strings.Join(parts, "/")
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" || p == "." {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+func decodeNode(node any, dst any) error {
+	b, err := json.Marshal(node)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// ========== JSON Pointer (RFC 6901) ==========
+
+func escapePointerToken(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func unescapePointerToken(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+}
+
+// splitPointer separa um JSON Pointer ("/a/b~1c") nos tokens já decodificados.
+func splitPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("%w: %q deve começar com /", ErrInvalidPointer, ptr)
+	}
+	parts := strings.Split(ptr[1:], "/")
+	for i, p := range parts {
+		if !validPointerToken(p) {
+			return nil, fmt.Errorf("%w: escape inválido em %q", ErrInvalidPointer, p)
+		}
+		parts[i] = unescapePointerToken(p)
+	}
+	return parts, nil
+}
+
+// validPointerToken confere que todo "~" de tok é seguido de 0 ou 1 (RFC 6901).
+func validPointerToken(tok string) bool {
+	for i := 0; i < len(tok); i++ {
+		if tok[i] == '~' {
+			if i+1 == len(tok) || (tok[i+1] != '0' && tok[i+1] != '1') {
+				return false
+			}
+			i++
+		}
+	}
+	return true
+}
+
+// pointerGet navega uma árvore genérica (map[string]any / []any) por um JSON Pointer.
+func pointerGet(root any, ptr string) (any, error) {
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	node := root
+	for _, tok := range tokens {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[tok]
+			if !ok {
+				return nil, ErrRefNotFound
+			}
+			node = v
+		case []any:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(n) || (len(tok) > 1 && tok[0] == '0') {
+				return nil, ErrRefNotFound
+			}
+			node = n[i]
+		default:
+			return nil, ErrRefNotFound
+		}
+	}
+	return node, nil
+}
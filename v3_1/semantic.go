@@ -0,0 +1,374 @@
+package oas
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ========== Validação semântica ==========
+//
+// Regras que cruzam partes do documento (templates x parâmetros, operationId
+// x links, ...). Rodam dentro de Document.Validate, sobre os valores já com
+// $ref resolvidos.
+
+// resolveParameter devolve o parâmetro resolvido (nil se o $ref não resolve;
+// esse erro já é apontado pela validação estrutural).
+func (v *docValidator) resolveParameter(p ParameterOrRef) *Parameter {
+	param, err := v.r.ResolveParameter(p)
+	if err != nil {
+		return nil
+	}
+	return param
+}
+
+// parameterKey identifica um parâmetro pelo par (name, in); nomes de header
+// não diferenciam maiúsculas.
+func parameterKey(p *Parameter) string {
+	name := p.Name
+	if p.In == InHeader {
+		name = strings.ToLower(name)
+	}
+	return string(p.In) + ":" + name
+}
+
+// uniqueParameters aponta pares (name, in) repetidos numa lista de parâmetros.
+func (v *docValidator) uniqueParameters(ptr string, params []ParameterOrRef) {
+	seen := map[string]bool{}
+	for i, p := range params {
+		param := v.resolveParameter(p)
+		if param == nil || param.Name == "" {
+			continue
+		}
+		key := parameterKey(param)
+		if seen[key] {
+			v.add(ptrJoin(ptr, "parameters", strconv.Itoa(i)), CodeDuplicate, "parâmetro %q em %s repetido", param.Name, param.In)
+		}
+		seen[key] = true
+	}
+}
+
+// pathTemplates confere cada template de Paths: variáveis x parâmetros
+// "in: path" declarados e templates que colidem entre si.
+func (v *docValidator) pathTemplates() {
+	shapes := map[string]string{}
+	for _, path := range sortedKeys(v.doc.Paths) {
+		ptr := ptrJoin("/paths", path)
+		vars := v.templateVars(ptr, path)
+
+		// "/users/{id}" e "/users/{userId}" são o mesmo path
+		shape := reTemplateVar.ReplaceAllString(path, "{}")
+		if other, ok := shapes[shape]; ok {
+			v.add(ptr, CodeConflict, "template colide com %s", other)
+		} else {
+			shapes[shape] = path
+		}
+
+		item := v.doc.Paths[path]
+		pi, err := v.r.ResolvePathItem(item)
+		if err != nil || pi == nil {
+			continue
+		}
+		v.pathParameters(ptr, pi, vars, item.Ref != nil)
+	}
+}
+
+// templateVars extrai as variáveis do template, na ordem, apontando templates
+// malformados e variáveis repetidas.
+func (v *docValidator) templateVars(ptr, path string) []string {
+	matches := reTemplateVar.FindAllStringSubmatch(path, -1)
+	if strings.Count(path, "{") != len(matches) || strings.Count(path, "}") != len(matches) {
+		v.add(ptr, CodeInvalidName, "template malformado")
+	}
+	vars := make([]string, 0, len(matches))
+	seen := map[string]bool{}
+	for _, m := range matches {
+		if seen[m[1]] {
+			v.add(ptr, CodeDuplicate, "variável {%s} repetida no template", m[1])
+			continue
+		}
+		seen[m[1]] = true
+		vars = append(vars, m[1])
+	}
+	return vars
+}
+
+// pathParameters cruza as variáveis do template com os parâmetros "in: path"
+// do path item e de cada operação. Se o path item veio de um $ref, os erros
+// apontam para o próprio path.
+func (v *docValidator) pathParameters(ptr string, pi *PathItem, vars []string, viaRef bool) {
+	at := func(p string) string {
+		if viaRef {
+			return ptr
+		}
+		return p
+	}
+	inTemplate := map[string]bool{}
+	for _, name := range vars {
+		inTemplate[name] = true
+	}
+	// declara os parâmetros de path da lista e aponta os que sobram
+	declare := func(declared map[string]bool, base string, params []ParameterOrRef) {
+		for i, p := range params {
+			param := v.resolveParameter(p)
+			if param == nil || param.In != InPath {
+				continue
+			}
+			declared[param.Name] = true
+			if !inTemplate[param.Name] {
+				v.add(at(ptrJoin(base, "parameters", strconv.Itoa(i))), CodeUnused, "parâmetro de path %q não aparece no template", param.Name)
+			}
+		}
+	}
+
+	common := map[string]bool{}
+	declare(common, ptr, pi.Parameters)
+	for _, o := range pathItemOperations(pi) {
+		opPtr := ptrJoin(ptr, o.method)
+		declared := make(map[string]bool, len(common))
+		for name := range common {
+			declared[name] = true
+		}
+		declare(declared, opPtr, o.op.Parameters)
+		for _, name := range vars {
+			if !declared[name] {
+				v.add(at(opPtr), CodeUndeclared, "parâmetro de path {%s} não declarado", name)
+			}
+		}
+	}
+}
+
+// operationSite é uma operação alcançável pelo documento.
+type operationSite struct {
+	ptr  string   // ponteiro lógico, com os $ref já seguidos
+	keys []string // ponteiros que identificam a operação num operationRef
+	op   *Operation
+}
+
+// operations lista as operações de Paths, Webhooks e dos Callbacks de cada
+// operação, seguindo $ref. Com path item via $ref interno, o ponteiro do alvo
+// também identifica a operação.
+func (v *docValidator) operations() []operationSite {
+	var out []operationSite
+	active := map[string]bool{} // $ref no caminho atual, contra ciclos
+	follow := func(ref *Reference) bool {
+		if ref == nil {
+			return true
+		}
+		if active[ref.Ref] {
+			return false
+		}
+		active[ref.Ref] = true
+		return true
+	}
+	release := func(ref *Reference) {
+		if ref != nil {
+			delete(active, ref.Ref)
+		}
+	}
+
+	var pathItem func(ptr string, item PathItemOrRef)
+	pathItem = func(ptr string, item PathItemOrRef) {
+		pi, err := v.r.ResolvePathItem(item)
+		if err != nil || pi == nil || !follow(item.Ref) {
+			return
+		}
+		defer release(item.Ref)
+		target := ""
+		if item.Ref != nil {
+			if u, fragment, err := v.r.target(item.Ref.Ref); err == nil && sameDocument(u, v.r.base) {
+				target = fragment
+			}
+		}
+		for _, o := range pathItemOperations(pi) {
+			site := operationSite{ptr: ptrJoin(ptr, o.method), op: o.op}
+			site.keys = append(site.keys, site.ptr)
+			if target != "" {
+				site.keys = append(site.keys, ptrJoin(target, o.method))
+			}
+			out = append(out, site)
+			for _, name := range sortedKeys(o.op.Callbacks) {
+				cb := o.op.Callbacks[name]
+				callback, err := v.r.ResolveCallback(cb)
+				if err != nil || callback == nil || !follow(cb.Ref) {
+					continue
+				}
+				for _, expr := range sortedKeys(*callback) {
+					pathItem(ptrJoin(site.ptr, "callbacks", name, expr), (*callback)[expr])
+				}
+				release(cb.Ref)
+			}
+		}
+	}
+	for _, path := range sortedKeys(v.doc.Paths) {
+		pathItem(ptrJoin("/paths", path), v.doc.Paths[path])
+	}
+	for _, name := range sortedKeys(v.doc.Webhooks) {
+		pathItem(ptrJoin("/webhooks", name), v.doc.Webhooks[name])
+	}
+	return out
+}
+
+// operationIDs aponta operationId repetidos.
+func (v *docValidator) operationIDs(ops []operationSite) {
+	first := map[string]string{}
+	for _, o := range ops {
+		if o.op.OperationID == nil {
+			continue
+		}
+		id := *o.op.OperationID
+		if ptr, ok := first[id]; ok {
+			v.add(o.ptr+"/operationId", CodeDuplicate, "operationId %q repetido (já usado em %s)", id, ptr)
+			continue
+		}
+		first[id] = o.ptr
+	}
+}
+
+// links confere se operationId e operationRef de cada Link apontam para uma
+// operação existente. Links e responses via $ref são conferidos onde são
+// definidos (em components).
+func (v *docValidator) links(ops []operationSite) {
+	ids := map[string]bool{}
+	targets := map[string]bool{}
+	for _, o := range ops {
+		if o.op.OperationID != nil {
+			ids[*o.op.OperationID] = true
+		}
+		for _, k := range o.keys {
+			targets[k] = true
+		}
+	}
+	check := func(ptr string, l LinkOrRef) {
+		if l.Ref != nil || l.Link == nil {
+			return
+		}
+		if id := l.Link.OperationID; id != nil && !ids[*id] {
+			v.add(ptr+"/operationId", CodeUndeclared, "operationId %q não existe", *id)
+		}
+		if ref := l.Link.OperationRef; ref != nil {
+			v.operationRef(ptr+"/operationRef", *ref, targets)
+		}
+	}
+	response := func(ptr string, r ResponseOrRef) {
+		if r.Ref != nil || r.Resp == nil {
+			return
+		}
+		for _, name := range sortedKeys(r.Resp.Links) {
+			check(ptrJoin(ptr, "links", name), r.Resp.Links[name])
+		}
+	}
+	for _, o := range ops {
+		for _, code := range sortedKeys(o.op.Responses) {
+			response(ptrJoin(o.ptr, "responses", code), o.op.Responses[code])
+		}
+	}
+	if c := v.doc.Components; c != nil {
+		for _, name := range sortedKeys(c.Responses) {
+			response(ptrJoin("/components/responses", name), c.Responses[name])
+		}
+		for _, name := range sortedKeys(c.Links) {
+			check(ptrJoin("/components/links", name), c.Links[name])
+		}
+	}
+}
+
+// operationRef confere o alvo de um operationRef. No próprio documento ele
+// precisa ser uma das operações; externos só são conferidos com RefLoader.
+func (v *docValidator) operationRef(ptr, ref string, targets map[string]bool) {
+	u, fragment, err := v.r.target(ref)
+	if err != nil {
+		v.add(ptr, CodeInvalidRef, "operationRef %q: %v", ref, err)
+		return
+	}
+	if sameDocument(u, v.r.base) {
+		if !targets[fragment] {
+			v.add(ptr, CodeUndeclared, "operationRef %q não aponta para uma operação", ref)
+		}
+		return
+	}
+	node, _, err := v.r.lookup(ref)
+	if errors.Is(err, ErrExternalRef) {
+		return
+	}
+	var op Operation
+	if err == nil {
+		if _, isObject := node.(map[string]any); !isObject {
+			err = ErrRefNotFound
+		} else {
+			err = decodeNode(node, &op)
+		}
+	}
+	if err != nil {
+		v.add(ptr, CodeUndeclared, "operationRef %q não aponta para uma operação: %v", ref, err)
+	}
+}
+
+// UnusedComponents lista, como avisos (SeverityWarning, código unused), os
+// componentes que nada no documento usa: nem $ref, nem mapping de
+// discriminator, nem (para securitySchemes) um requisito de security.
+// Documentos só com components (bibliotecas de schemas) não são conferidos.
+// Validate não faz essa conferência.
+func (d *Document) UnusedComponents(opts ...ResolverOption) ValidationErrors {
+	v := &docValidator{doc: d, r: NewResolver(d, opts...)}
+	v.unusedComponents(v.operations())
+	return v.errs
+}
+
+func (v *docValidator) unusedComponents(ops []operationSite) {
+	c := v.doc.Components
+	if c == nil || (v.doc.Paths == nil && v.doc.Webhooks == nil) {
+		return
+	}
+	used := map[string]bool{}
+	mark := func(ref string) {
+		u, fragment, err := v.r.target(ref)
+		if err != nil || !sameDocument(u, v.r.base) {
+			return
+		}
+		if tokens, err := splitPointer(fragment); err == nil && len(tokens) >= 3 && tokens[0] == "components" {
+			used[ptrJoin("/components", tokens[1], tokens[2])] = true
+		}
+	}
+	walkObjects(v.r.root, "", func(obj map[string]any) {
+		if ref, ok := obj["$ref"].(string); ok {
+			mark(ref)
+		}
+		d, _ := obj["discriminator"].(map[string]any)
+		mapping, _ := d["mapping"].(map[string]any)
+		for _, target := range mapping {
+			if ref, ok := target.(string); ok && strings.Contains(ref, "#") {
+				mark(ref)
+			} else if ok {
+				used[ptrJoin("/components/schemas", ref)] = true
+			}
+		}
+	})
+	requirements := v.doc.Security
+	for _, o := range ops {
+		requirements = append(requirements, o.op.Security...)
+	}
+	for _, req := range requirements {
+		for name := range req {
+			used[ptrJoin("/components/securitySchemes", name)] = true
+		}
+	}
+
+	check := func(kind string, names []string) {
+		for _, name := range names {
+			if ptr := ptrJoin("/components", kind, name); !used[ptr] {
+				v.warn(ptr, CodeUnused, "componente não usado")
+			}
+		}
+	}
+	check("schemas", sortedKeys(c.Schemas))
+	check("responses", sortedKeys(c.Responses))
+	check("parameters", sortedKeys(c.Parameters))
+	check("examples", sortedKeys(c.Examples))
+	check("requestBodies", sortedKeys(c.RequestBodies))
+	check("headers", sortedKeys(c.Headers))
+	check("securitySchemes", sortedKeys(c.SecuritySchemes))
+	check("links", sortedKeys(c.Links))
+	check("callbacks", sortedKeys(c.Callbacks))
+	check("pathItems", sortedKeys(c.PathItems))
+}
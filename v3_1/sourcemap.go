@@ -0,0 +1,206 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ========== Source map ==========
+
+// Position localiza um nó no arquivo de origem (linha e coluna a partir de 1).
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String devolve "arquivo:linha:coluna" (ou "linha:coluna" sem arquivo).
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// SourceMap associa o JSON Pointer de cada nó do documento à sua posição na
+// origem. Membros de objetos apontam para a chave; itens de array, para o item.
+type SourceMap map[string]Position
+
+// Lookup devolve a posição de ptr ou, se o nó não existe na origem (ex.: um
+// campo obrigatório ausente), a do ancestral mais próximo.
+func (m SourceMap) Lookup(ptr string) (Position, bool) {
+	for {
+		if pos, ok := m[ptr]; ok {
+			return pos, true
+		}
+		if ptr == "" {
+			return Position{}, false
+		}
+		i := len(ptr) - 1
+		for i > 0 && ptr[i] != '/' {
+			i--
+		}
+		ptr = ptr[:i]
+	}
+}
+
+// at devolve a posição exata de ptr (nil se desconhecida).
+func (m SourceMap) at(ptr string) *Position {
+	if pos, ok := m[ptr]; ok {
+		return &pos
+	}
+	return nil
+}
+
+// nearest é como Lookup, mas devolve nil se nada for encontrado.
+func (m SourceMap) nearest(ptr string) *Position {
+	if pos, ok := m.Lookup(ptr); ok {
+		return &pos
+	}
+	return nil
+}
+
+// SourceMap devolve as posições registradas por Load/LoadYAML com
+// WithSourceMap (nil se o documento não veio de lá).
+func (d *Document) SourceMap() SourceMap {
+	return d.sourceMap
+}
+
+// buildSourceMap mapeia os nós de data no formato indicado.
+func buildSourceMap(data []byte, format Format, file string) (SourceMap, error) {
+	if format == FormatYAML {
+		return yamlSourceMap(data, file)
+	}
+	return jsonSourceMap(data, file)
+}
+
+func yamlSourceMap(b []byte, file string) (SourceMap, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, err
+	}
+	n := &root
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	m := SourceMap{"": {File: file, Line: n.Line, Column: n.Column}}
+	if err := newYAMLExpander().positions(m, n, "", file); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (e *yamlExpander) positions(m SourceMap, n *yaml.Node, ptr, file string) error {
+	return e.visit(n, func(n *yaml.Node) error {
+		switch n.Kind {
+		case yaml.SequenceNode:
+			for i, item := range n.Content {
+				p := ptrJoin(ptr, strconv.Itoa(i))
+				m[p] = Position{File: file, Line: item.Line, Column: item.Column}
+				if err := e.positions(m, item, p, file); err != nil {
+					return err
+				}
+			}
+		case yaml.MappingNode:
+			pairs, err := e.mappingPairs(n)
+			if err != nil {
+				return err
+			}
+			for _, pair := range pairs {
+				p := ptrJoin(ptr, pair.key)
+				m[p] = Position{File: file, Line: pair.keyNode.Line, Column: pair.keyNode.Column}
+				if err := e.positions(m, pair.value, p, file); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func jsonSourceMap(data []byte, file string) (SourceMap, error) {
+	lines := newLineIndex(data, file)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	m := SourceMap{"": lines.position(tokenStart(data, 0))}
+
+	var walk func(ptr string) error
+	walk = func(ptr string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				start := tokenStart(data, int(dec.InputOffset()))
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				p := ptrJoin(ptr, key.(string))
+				m[p] = lines.position(start)
+				if err := walk(p); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				p := ptrJoin(ptr, strconv.Itoa(i))
+				m[p] = lines.position(tokenStart(data, int(dec.InputOffset())))
+				if err := walk(p); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		}
+		return err
+	}
+	if err := walk(""); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return m, nil
+}
+
+// tokenStart pula espaços e separadores a partir de off até o próximo token.
+func tokenStart(data []byte, off int) int {
+	for off < len(data) {
+		switch data[off] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			off++
+		default:
+			return off
+		}
+	}
+	return off
+}
+
+// lineIndex converte offsets em linha e coluna (em runas, a partir de 1).
+type lineIndex struct {
+	data   []byte
+	file   string
+	starts []int
+}
+
+func newLineIndex(data []byte, file string) *lineIndex {
+	starts := []int{0}
+	for i, c := range data {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &lineIndex{data: data, file: file, starts: starts}
+}
+
+func (l *lineIndex) position(off int) Position {
+	line := sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > off }) - 1
+	col := utf8.RuneCount(l.data[l.starts[line]:off]) + 1
+	return Position{File: l.file, Line: line + 1, Column: col}
+}
@@ -8,9 +8,13 @@ import (
 
 // ========== Utilidades para unions / helpers ==========
 
-// Reference representa um $ref simples.
+// Reference representa um $ref. Summary e Description (OAS 3.1) substituem
+// os do objeto apontado, quando ele tem esses campos; em schemas, os keywords
+// ao lado do $ref ficam em SchemaOrRef.Schema.
 type Reference struct {
-	Ref string `json:"$ref"`
+	Ref         string  `json:"$ref"`
+	Summary     *string `json:"summary,omitempty"`
+	Description *string `json:"description,omitempty"`
 }
 
 func isRefObject(raw map[string]any) (string, bool) {
@@ -22,6 +26,23 @@ func isRefObject(raw map[string]any) (string, bool) {
 	return "", false
 }
 
+// refObject devolve o Reference Object de raw (com summary e description),
+// se raw tiver um $ref.
+func refObject(raw map[string]any) (*Reference, bool) {
+	ref, ok := isRefObject(raw)
+	if !ok {
+		return nil, false
+	}
+	out := &Reference{Ref: ref}
+	if v, ok := raw["summary"].(string); ok {
+		out.Summary = &v
+	}
+	if v, ok := raw["description"].(string); ok {
+		out.Description = &v
+	}
+	return out, true
+}
+
 // StringOrArray aceita "type" como string ou []string (OpenAPI 3.1 / JSON Schema 2020-12).
 type StringOrArray struct {
 	One  *string
@@ -49,20 +70,31 @@ func (t StringOrArray) MarshalJSON() ([]byte, error) {
 	return json.Marshal(t.Many)
 }
 
-// SchemaOrRef: ou um objeto Schema, ou um $ref.
+// SchemaOrRef: um objeto Schema, um $ref ou um schema booleano (true aceita
+// qualquer valor, false nenhum). Em 3.1 o $ref pode vir com outros keywords
+// ao lado ({"$ref": "...", "description": "..."}): nesse caso Ref e Schema
+// são preenchidos juntos, e Schema guarda os irmãos.
 type SchemaOrRef struct {
 	Schema *Schema
 	Ref    *Reference
+	Bool   *bool
 }
 
 func (s *SchemaOrRef) UnmarshalJSON(b []byte) error {
+	var bo bool
+	if err := json.Unmarshal(b, &bo); err == nil {
+		s.Bool = &bo
+		return nil
+	}
 	var raw map[string]any
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
 	if ref, ok := isRefObject(raw); ok {
 		s.Ref = &Reference{Ref: ref}
-		return nil
+		if len(raw) == 1 {
+			return nil
+		}
 	}
 	var sch Schema
 	if err := json.Unmarshal(b, &sch); err != nil {
@@ -73,7 +105,24 @@ func (s *SchemaOrRef) UnmarshalJSON(b []byte) error {
 }
 
 func (s SchemaOrRef) MarshalJSON() ([]byte, error) {
-	if s.Ref != nil {
+	switch {
+	case s.Bool != nil:
+		return json.Marshal(*s.Bool)
+	case s.Ref != nil && s.Schema != nil:
+		// "$ref" primeiro, seguido dos irmãos na ordem de Schema
+		ref, err := json.Marshal(s.Ref)
+		if err != nil {
+			return nil, err
+		}
+		siblings, err := json.Marshal(s.Schema)
+		if err != nil {
+			return nil, err
+		}
+		if len(siblings) == len("{}") {
+			return ref, nil
+		}
+		return append(append(ref[:len(ref)-1], ','), siblings[1:]...), nil
+	case s.Ref != nil:
 		return json.Marshal(s.Ref)
 	}
 	return json.Marshal(s.Schema)
@@ -87,7 +136,7 @@ type Items struct {
 
 func (it *Items) UnmarshalJSON(b []byte) error {
 	var one SchemaOrRef
-	if err := json.Unmarshal(b, &one); err == nil && (one.Ref != nil || one.Schema != nil) {
+	if err := json.Unmarshal(b, &one); err == nil && (one.Ref != nil || one.Schema != nil || one.Bool != nil) {
 		it.Single = &one
 		return nil
 	}
@@ -150,23 +199,30 @@ type Document struct {
 	Security          []SecurityRequirement    `json:"security,omitempty"`
 	Tags              []Tag                    `json:"tags,omitempty"`
 	ExternalDocs      *ExternalDocumentation   `json:"externalDocs,omitempty"`
+
+	Extensions      Extensions `json:"-"`
+	PathsExtensions Extensions `json:"-"` // chaves "x-*" do objeto paths
+
+	sourceMap SourceMap // preenchido por Load/LoadYAML com WithSourceMap
 }
 
 // Info
 type Info struct {
-	Title          string   `json:"title"`
-	Version        string   `json:"version"`
-	Summary        *string  `json:"summary,omitempty"`
-	Description    *string  `json:"description,omitempty"`
-	TermsOfService *string  `json:"termsOfService,omitempty"`
-	Contact        *Contact `json:"contact,omitempty"`
-	License        *License `json:"license,omitempty"`
+	Title          string     `json:"title"`
+	Version        string     `json:"version"`
+	Summary        *string    `json:"summary,omitempty"`
+	Description    *string    `json:"description,omitempty"`
+	TermsOfService *string    `json:"termsOfService,omitempty"`
+	Contact        *Contact   `json:"contact,omitempty"`
+	License        *License   `json:"license,omitempty"`
+	Extensions     Extensions `json:"-"`
 }
 
 type Contact struct {
-	Name  *string `json:"name,omitempty"`
-	URL   *string `json:"url,omitempty"`
-	Email *string `json:"email,omitempty"`
+	Name       *string    `json:"name,omitempty"`
+	URL        *string    `json:"url,omitempty"`
+	Email      *string    `json:"email,omitempty"`
+	Extensions Extensions `json:"-"`
 }
 
 func NewContact(name string, url string, email string) *Contact {
@@ -174,9 +230,10 @@ func NewContact(name string, url string, email string) *Contact {
 }
 
 type License struct {
-	Name string  `json:"name"`
-	ID   *string `json:"identifier,omitempty"` // OAS 3.1
-	URL  *string `json:"url,omitempty"`
+	Name       string     `json:"name"`
+	ID         *string    `json:"identifier,omitempty"` // OAS 3.1
+	URL        *string    `json:"url,omitempty"`
+	Extensions Extensions `json:"-"`
 }
 
 func NewLicense(name string, urlAndEmail ...string) *License {
@@ -196,12 +253,14 @@ type Server struct {
 	URL         string                    `json:"url"`
 	Description *string                   `json:"description,omitempty"`
 	Variables   map[string]ServerVariable `json:"variables,omitempty"`
+	Extensions  Extensions                `json:"-"`
 }
 
 type ServerVariable struct {
-	Enum        []string `json:"enum,omitempty"`
-	Default     string   `json:"default"`
-	Description *string  `json:"description,omitempty"`
+	Enum        []string   `json:"enum,omitempty"`
+	Default     string     `json:"default"`
+	Description *string    `json:"description,omitempty"`
+	Extensions  Extensions `json:"-"`
 }
 
 // ========== Paths / PathItem / Operation ==========
@@ -219,8 +278,8 @@ func (p *PathItemOrRef) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
-	if ref, ok := isRefObject(raw); ok {
-		p.Ref = &Reference{Ref: ref}
+	if ref, ok := refObject(raw); ok {
+		p.Ref = ref
 		return nil
 	}
 	var pi PathItem
@@ -252,6 +311,7 @@ type PathItem struct {
 	Trace       *Operation       `json:"trace,omitempty"`
 	Servers     []Server         `json:"servers,omitempty"`
 	Parameters  []ParameterOrRef `json:"parameters,omitempty"`
+	Extensions  Extensions       `json:"-"`
 }
 
 // Operation
@@ -268,11 +328,15 @@ type Operation struct {
 	Deprecated   *bool                    `json:"deprecated,omitempty"`
 	Security     []SecurityRequirement    `json:"security,omitempty"`
 	Servers      []Server                 `json:"servers,omitempty"`
+	Extensions   Extensions               `json:"-"`
+
+	ResponsesExtensions Extensions `json:"-"` // chaves "x-*" do objeto responses
 }
 
 type ExternalDocumentation struct {
-	Description *string `json:"description,omitempty"`
-	URL         string  `json:"url"`
+	Description *string    `json:"description,omitempty"`
+	URL         string     `json:"url"`
+	Extensions  Extensions `json:"-"`
 }
 
 // ========== Parameters / RequestBody / MediaType / Encoding ==========
@@ -313,6 +377,7 @@ type Parameter struct {
 	Example         any                     `json:"example,omitempty"`
 	Examples        map[string]ExampleOrRef `json:"examples,omitempty"`
 	Content         map[string]MediaType    `json:"content,omitempty"`
+	Extensions      Extensions              `json:"-"`
 }
 
 type ParameterOrRef struct {
@@ -325,8 +390,8 @@ func (p *ParameterOrRef) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
-	if ref, ok := isRefObject(raw); ok {
-		p.Ref = &Reference{Ref: ref}
+	if ref, ok := refObject(raw); ok {
+		p.Ref = ref
 		return nil
 	}
 	var pr Parameter
@@ -349,6 +414,7 @@ type RequestBody struct {
 	Description *string              `json:"description,omitempty"`
 	Content     map[string]MediaType `json:"content"`
 	Required    *bool                `json:"required,omitempty"`
+	Extensions  Extensions           `json:"-"`
 }
 
 type RequestBodyOrRef struct {
@@ -361,8 +427,8 @@ func (r *RequestBodyOrRef) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
-	if ref, ok := isRefObject(raw); ok {
-		r.Ref = &Reference{Ref: ref}
+	if ref, ok := refObject(raw); ok {
+		r.Ref = ref
 		return nil
 	}
 	var rb RequestBody
@@ -382,10 +448,11 @@ func (r RequestBodyOrRef) MarshalJSON() ([]byte, error) {
 
 // MediaType
 type MediaType struct {
-	Schema   *SchemaOrRef            `json:"schema,omitempty"`
-	Example  any                     `json:"example,omitempty"`
-	Examples map[string]ExampleOrRef `json:"examples,omitempty"`
-	Encoding map[string]Encoding     `json:"encoding,omitempty"`
+	Schema     *SchemaOrRef            `json:"schema,omitempty"`
+	Example    any                     `json:"example,omitempty"`
+	Examples   map[string]ExampleOrRef `json:"examples,omitempty"`
+	Encoding   map[string]Encoding     `json:"encoding,omitempty"`
+	Extensions Extensions              `json:"-"`
 }
 
 // Encoding
@@ -395,6 +462,7 @@ type Encoding struct {
 	Style         *ParameterStyle        `json:"style,omitempty"`
 	Explode       *bool                  `json:"explode,omitempty"`
 	AllowReserved *bool                  `json:"allowReserved,omitempty"`
+	Extensions    Extensions             `json:"-"`
 }
 
 // ========== Responses / Response / Header / Example / Link / Callback ==========
@@ -406,6 +474,7 @@ type Response struct {
 	Headers     map[string]HeaderOrRef `json:"headers,omitempty"`
 	Content     map[string]MediaType   `json:"content,omitempty"`
 	Links       map[string]LinkOrRef   `json:"links,omitempty"`
+	Extensions  Extensions             `json:"-"`
 }
 
 type ResponseOrRef struct {
@@ -418,8 +487,8 @@ func (r *ResponseOrRef) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
-	if ref, ok := isRefObject(raw); ok {
-		r.Ref = &Reference{Ref: ref}
+	if ref, ok := refObject(raw); ok {
+		r.Ref = ref
 		return nil
 	}
 	var rr Response
@@ -448,6 +517,7 @@ type Header struct {
 	Example     any                     `json:"example,omitempty"`
 	Examples    map[string]ExampleOrRef `json:"examples,omitempty"`
 	Content     map[string]MediaType    `json:"content,omitempty"`
+	Extensions  Extensions              `json:"-"`
 }
 
 type HeaderOrRef struct {
@@ -460,8 +530,8 @@ func (h *HeaderOrRef) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
-	if ref, ok := isRefObject(raw); ok {
-		h.Ref = &Reference{Ref: ref}
+	if ref, ok := refObject(raw); ok {
+		h.Ref = ref
 		return nil
 	}
 	var hd Header
@@ -481,10 +551,11 @@ func (h HeaderOrRef) MarshalJSON() ([]byte, error) {
 
 // Example
 type Example struct {
-	Summary       *string `json:"summary,omitempty"`
-	Description   *string `json:"description,omitempty"`
-	Value         any     `json:"value,omitempty"`
-	ExternalValue *string `json:"externalValue,omitempty"`
+	Summary       *string    `json:"summary,omitempty"`
+	Description   *string    `json:"description,omitempty"`
+	Value         any        `json:"value,omitempty"`
+	ExternalValue *string    `json:"externalValue,omitempty"`
+	Extensions    Extensions `json:"-"`
 }
 
 type ExampleOrRef struct {
@@ -497,8 +568,8 @@ func (e *ExampleOrRef) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
-	if ref, ok := isRefObject(raw); ok {
-		e.Ref = &Reference{Ref: ref}
+	if ref, ok := refObject(raw); ok {
+		e.Ref = ref
 		return nil
 	}
 	var ex Example
@@ -524,6 +595,7 @@ type Link struct {
 	RequestBody  any          `json:"requestBody,omitempty"`
 	Description  *string      `json:"description,omitempty"`
 	Server       *Server      `json:"server,omitempty"`
+	Extensions   Extensions   `json:"-"`
 }
 
 type LinkOrRef struct {
@@ -536,8 +608,8 @@ func (l *LinkOrRef) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
-	if ref, ok := isRefObject(raw); ok {
-		l.Ref = &Reference{Ref: ref}
+	if ref, ok := refObject(raw); ok {
+		l.Ref = ref
 		return nil
 	}
 	var lk Link
@@ -559,8 +631,9 @@ func (l LinkOrRef) MarshalJSON() ([]byte, error) {
 type Callback map[string]PathItemOrRef
 
 type CallbackOrRef struct {
-	Callback *Callback
-	Ref      *Reference
+	Callback   *Callback
+	Ref        *Reference
+	Extensions Extensions // chaves "x-*" do Callback
 }
 
 func (c *CallbackOrRef) UnmarshalJSON(b []byte) error {
@@ -568,8 +641,8 @@ func (c *CallbackOrRef) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
-	if ref, ok := isRefObject(raw); ok {
-		c.Ref = &Reference{Ref: ref}
+	if ref, ok := refObject(raw); ok {
+		c.Ref = ref
 		return nil
 	}
 	var cb Callback
@@ -577,14 +650,18 @@ func (c *CallbackOrRef) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	c.Callback = &cb
-	return nil
+	return extensionsOf(b, &c.Extensions)
 }
 
 func (c CallbackOrRef) MarshalJSON() ([]byte, error) {
 	if c.Ref != nil {
 		return json.Marshal(c.Ref)
 	}
-	return json.Marshal(c.Callback)
+	b, err := json.Marshal(c.Callback)
+	if err != nil {
+		return nil, err
+	}
+	return appendExtensions(b, c.Extensions)
 }
 
 // ========== Components / Security / Tags ==========
@@ -600,6 +677,7 @@ type Components struct {
 	Links           map[string]LinkOrRef           `json:"links,omitempty"`
 	Callbacks       map[string]CallbackOrRef       `json:"callbacks,omitempty"`
 	PathItems       map[string]PathItemOrRef       `json:"pathItems,omitempty"` // OAS 3.1
+	Extensions      Extensions                     `json:"-"`
 }
 
 type SecurityRequirement map[string][]string
@@ -632,7 +710,8 @@ type SecurityScheme struct {
 	Flows *OAuthFlows `json:"flows,omitempty"`
 
 	// openIdConnect
-	OpenIDConnectURL *string `json:"openIdConnectUrl,omitempty"`
+	OpenIDConnectURL *string    `json:"openIdConnectUrl,omitempty"`
+	Extensions       Extensions `json:"-"`
 }
 
 type SecuritySchemeOrRef struct {
@@ -645,8 +724,8 @@ func (s *SecuritySchemeOrRef) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
-	if ref, ok := isRefObject(raw); ok {
-		s.Ref = &Reference{Ref: ref}
+	if ref, ok := refObject(raw); ok {
+		s.Ref = ref
 		return nil
 	}
 	var sc SecurityScheme
@@ -670,6 +749,7 @@ type OAuthFlows struct {
 	Password          *OAuthFlow `json:"password,omitempty"`
 	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
 	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	Extensions        Extensions `json:"-"`
 }
 
 type OAuthFlow struct {
@@ -677,6 +757,7 @@ type OAuthFlow struct {
 	TokenURL         string            `json:"tokenUrl,omitempty"`
 	RefreshURL       *string           `json:"refreshUrl,omitempty"`
 	Scopes           map[string]string `json:"scopes"`
+	Extensions       Extensions        `json:"-"`
 }
 
 // Tags
@@ -684,6 +765,7 @@ type Tag struct {
 	Name         string                 `json:"name"`
 	Description  *string                `json:"description,omitempty"`
 	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty"`
+	Extensions   Extensions             `json:"-"`
 }
 
 var (
@@ -707,10 +789,22 @@ type Required []string
 type Examples []any
 type Enum []any
 type PatternProperties map[string]SchemaOrRef
+type Defs map[string]SchemaOrRef
+type DependentSchemas map[string]SchemaOrRef
+type DependentRequired map[string][]string
 
 // Schema representa o dialeto JSON Schema 2020-12 na medida necessária para OAS 3.1.
 // (Campos menos comuns podem ser adicionados no mesmo padrão.)
 type Schema struct {
+	// Core (JSON Schema 2020-12)
+	ID            *string `json:"$id,omitempty"`
+	Dialect       *string `json:"$schema,omitempty"`
+	Anchor        *string `json:"$anchor,omitempty"`
+	DynamicAnchor *string `json:"$dynamicAnchor,omitempty"`
+	DynamicRef    *string `json:"$dynamicRef,omitempty"`
+	Defs          Defs    `json:"$defs,omitempty"`
+	Comment       *string `json:"$comment,omitempty"`
+
 	// Meta
 	Title       *string  `json:"title,omitempty"`
 	Description *string  `json:"description,omitempty"`
@@ -731,6 +825,13 @@ type Schema struct {
 	AnyOf AnyOf `json:"anyOf,omitempty"`
 	Not   Not   `json:"not,omitempty"`
 
+	// Condicionais
+	If                *SchemaOrRef      `json:"if,omitempty"`
+	Then              *SchemaOrRef      `json:"then,omitempty"`
+	Else              *SchemaOrRef      `json:"else,omitempty"`
+	DependentSchemas  DependentSchemas  `json:"dependentSchemas,omitempty"`
+	DependentRequired DependentRequired `json:"dependentRequired,omitempty"`
+
 	// Objetos
 	Properties           Properties            `json:"properties,omitempty"`
 	Required             Required              `json:"required,omitempty"`
@@ -738,16 +839,20 @@ type Schema struct {
 	PatternProperties    PatternProperties     `json:"patternProperties,omitempty"`
 	MinProperties        *int                  `json:"minProperties,omitempty"`
 	MaxProperties        *int                  `json:"maxProperties,omitempty"`
+	PropertyNames        *SchemaOrRef          `json:"propertyNames,omitempty"`
+	// Unevaluated*: boolean ou schema, como additionalProperties
+	UnevaluatedProperties *AdditionalProperties `json:"unevaluatedProperties,omitempty"`
 
 	// Arrays
-	Items       *Items       `json:"items,omitempty"`
-	PrefixItems PrefixItems  `json:"prefixItems,omitempty"` // JSON Schema 2020-12
-	MinItems    *int         `json:"minItems,omitempty"`
-	MaxItems    *int         `json:"maxItems,omitempty"`
-	UniqueItems *bool        `json:"uniqueItems,omitempty"`
-	Contains    *SchemaOrRef `json:"contains,omitempty"`
-	MinContains *int         `json:"minContains,omitempty"`
-	MaxContains *int         `json:"maxContains,omitempty"`
+	Items            *Items                `json:"items,omitempty"`
+	PrefixItems      PrefixItems           `json:"prefixItems,omitempty"` // JSON Schema 2020-12
+	MinItems         *int                  `json:"minItems,omitempty"`
+	MaxItems         *int                  `json:"maxItems,omitempty"`
+	UniqueItems      *bool                 `json:"uniqueItems,omitempty"`
+	Contains         *SchemaOrRef          `json:"contains,omitempty"`
+	MinContains      *int                  `json:"minContains,omitempty"`
+	MaxContains      *int                  `json:"maxContains,omitempty"`
+	UnevaluatedItems *AdditionalProperties `json:"unevaluatedItems,omitempty"`
 
 	// Strings
 	MinLength *int    `json:"minLength,omitempty"`
@@ -755,6 +860,11 @@ type Schema struct {
 	Pattern   *string `json:"pattern,omitempty"`
 	Format    *string `json:"format,omitempty"`
 
+	// Conteúdo codificado em strings
+	ContentEncoding  *string      `json:"contentEncoding,omitempty"`
+	ContentMediaType *string      `json:"contentMediaType,omitempty"`
+	ContentSchema    *SchemaOrRef `json:"contentSchema,omitempty"`
+
 	// Numbers
 	MultipleOf       *float64 `json:"multipleOf,omitempty"`
 	Minimum          *float64 `json:"minimum,omitempty"`
@@ -763,27 +873,31 @@ type Schema struct {
 	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
 
 	// Misc (discriminator, xml, etc. — opcionais, comuns no mundo OAS)
-	Discriminator *Discriminator `json:"discriminator,omitempty"`
-	XML           *XML           `json:"xml,omitempty"`
+	Discriminator *Discriminator         `json:"discriminator,omitempty"`
+	XML           *XML                   `json:"xml,omitempty"`
+	ExternalDocs  *ExternalDocumentation `json:"externalDocs,omitempty"`
+	Example       any                    `json:"example,omitempty"` // OAS; prefira examples
 
-	// Extensões "x-*" ficam livres no nível de uso (MapStringAny) quando necessário.
+	Extensions Extensions `json:"-"`
 }
 
 type Mapping map[string]string
 
 // Discriminator (OAS)
 type Discriminator struct {
-	PropertyName string  `json:"propertyName"`
-	Mapping      Mapping `json:"mapping,omitempty"`
+	PropertyName string     `json:"propertyName"`
+	Mapping      Mapping    `json:"mapping,omitempty"`
+	Extensions   Extensions `json:"-"`
 }
 
 // XML (OAS)
 type XML struct {
-	Name      *string `json:"name,omitempty"`
-	Namespace *string `json:"namespace,omitempty"`
-	Prefix    *string `json:"prefix,omitempty"`
-	Attribute *bool   `json:"attribute,omitempty"`
-	Wrapped   *bool   `json:"wrapped,omitempty"`
+	Name       *string    `json:"name,omitempty"`
+	Namespace  *string    `json:"namespace,omitempty"`
+	Prefix     *string    `json:"prefix,omitempty"`
+	Attribute  *bool      `json:"attribute,omitempty"`
+	Wrapped    *bool      `json:"wrapped,omitempty"`
+	Extensions Extensions `json:"-"`
 }
 
 // ========== Helpers opcionais ==========
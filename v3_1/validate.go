@@ -0,0 +1,807 @@
+package oas
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// ========== Erros de validação ==========
+
+// Severity classifica um ValidationError.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Code identifica de forma estável o tipo de problema de um ValidationError.
+type Code string
+
+const (
+	CodeDecode            Code = "decode"             // valor não pôde ser decodificado
+	CodeRequired          Code = "required"           // campo obrigatório ausente
+	CodeInvalidValue      Code = "invalid-value"      // valor fora do permitido
+	CodeInvalidFormat     Code = "invalid-format"     // URI, e-mail, regex... malformado
+	CodeInvalidName       Code = "invalid-name"       // chave de mapa inválida (path, status, componente)
+	CodeMutuallyExclusive Code = "mutually-exclusive" // campos que não podem coexistir
+	CodeNotAllowed        Code = "not-allowed"        // campo não se aplica neste contexto
+	CodeDuplicate         Code = "duplicate"          // valor repetido onde deve ser único
+	CodeUndeclared        Code = "undeclared"         // referência por nome a algo não declarado
+	CodeInvalidRef        Code = "invalid-ref"        // $ref que não resolve
+	CodeUnused            Code = "unused"             // declarado mas sem uso (parâmetro fora do template, componente)
+	CodeConflict          Code = "conflict"           // definições que colidem entre si
+	CodeUnknownField      Code = "unknown-field"      // campo inexistente na especificação (WithStrict)
+)
+
+// ValidationError descreve um problema localizado no documento.
+type ValidationError struct {
+	Pointer  string // JSON Pointer do nó, ex.: "/paths/~1users/get/parameters/0" ("" é a raiz)
+	Code     Code
+	Severity Severity
+	Message  string
+	Err      error     // causa original, quando houver
+	Position *Position // posição na origem, se o documento tem source map
+}
+
+func (e *ValidationError) Error() string {
+	ptr := e.Pointer
+	if ptr == "" {
+		ptr = "(raiz)"
+	}
+	if e.Position != nil {
+		return e.Position.String() + ": " + ptr + ": " + e.Message
+	}
+	return ptr + ": " + e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Is compara pelo Code e, se informado no alvo, pelo Pointer:
+// errors.Is(err, &ValidationError{Code: CodeRequired}).
+func (e *ValidationError) Is(target error) bool {
+	t, ok := target.(*ValidationError)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code && (t.Pointer == "" || t.Pointer == e.Pointer)
+}
+
+// ValidationErrors agrega os problemas encontrados, na ordem do documento.
+// errors.Is e errors.As alcançam cada item (e as causas originais).
+type ValidationErrors []*ValidationError
+
+func (es ValidationErrors) Error() string {
+	lines := make([]string, len(es))
+	for i, e := range es {
+		lines[i] = e.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (es ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
+// HasErrors informa se há algum item com SeverityError.
+func (es ValidationErrors) HasErrors() bool {
+	for _, e := range es {
+		if e.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ========== Validação estrutural (OAS 3.1) ==========
+
+// Validate confere os campos obrigatórios e as restrições da especificação
+// OpenAPI 3.1 e devolve todos os problemas encontrados de uma vez como
+// ValidationErrors (nil se o documento é válido; avisos sozinhos não o
+// invalidam).
+// $ref internos pendentes também são apontados; os externos só são seguidos
+// se as opções configurarem um RefLoader.
+func (d *Document) Validate(opts ...ResolverOption) error {
+	v := &docValidator{doc: d, r: NewResolver(d, opts...)}
+	v.document()
+	if !v.errs.HasErrors() {
+		return nil
+	}
+	return v.errs
+}
+
+type docValidator struct {
+	doc  *Document
+	r    *Resolver
+	errs ValidationErrors
+}
+
+func (v *docValidator) add(ptr string, code Code, format string, args ...any) {
+	v.report(SeverityError, ptr, code, format, args...)
+}
+
+func (v *docValidator) warn(ptr string, code Code, format string, args ...any) {
+	v.report(SeverityWarning, ptr, code, format, args...)
+}
+
+func (v *docValidator) report(severity Severity, ptr string, code Code, format string, args ...any) {
+	v.errs = append(v.errs, &ValidationError{
+		Pointer:  ptr,
+		Code:     code,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		Position: v.doc.sourceMap.nearest(ptr),
+	})
+}
+
+var (
+	reComponentKey = regexp.MustCompile(`^[a-zA-Z0-9.\-_]+$`)
+	reStatusCode   = regexp.MustCompile(`^([1-5][0-9][0-9]|[1-5]XX)$`)
+	reTemplateVar  = regexp.MustCompile(`\{([^{}]+)\}`)
+	reAnchor       = regexp.MustCompile(`^[A-Za-z_][-A-Za-z0-9._]*$`)
+)
+
+func (v *docValidator) document() {
+	d := v.doc
+	switch {
+	case d.OpenAPI == "":
+		v.add("/openapi", CodeRequired, "campo obrigatório")
+	case !reOpenAPI31.MatchString(d.OpenAPI):
+		v.add("/openapi", CodeInvalidValue, "versão %q não é 3.1.x", d.OpenAPI)
+	}
+	if d.JSONSchemaDialect != nil {
+		v.uri("/jsonSchemaDialect", *d.JSONSchemaDialect)
+	}
+	v.info("/info", &d.Info)
+	for i := range d.Servers {
+		v.server(ptrJoin("/servers", fmt.Sprint(i)), &d.Servers[i])
+	}
+	if d.Paths == nil && d.Webhooks == nil && d.Components == nil {
+		v.add("", CodeRequired, "ao menos um entre paths, webhooks e components é obrigatório")
+	}
+	for _, k := range sortedKeys(d.Paths) {
+		ptr := ptrJoin("/paths", k)
+		if !strings.HasPrefix(k, "/") {
+			v.add(ptr, CodeInvalidName, "path deve começar com /")
+		}
+		v.pathItemOrRef(ptr, d.Paths[k])
+	}
+	v.pathTemplates()
+	for _, k := range sortedKeys(d.Webhooks) {
+		v.pathItemOrRef(ptrJoin("/webhooks", k), d.Webhooks[k])
+	}
+	v.components("/components", d.Components)
+	ops := v.operations()
+	v.operationIDs(ops)
+	v.links(ops)
+	v.security("/security", d.Security)
+	names := map[string]bool{}
+	for i, tag := range d.Tags {
+		ptr := ptrJoin("/tags", fmt.Sprint(i))
+		if tag.Name == "" {
+			v.add(ptr+"/name", CodeRequired, "campo obrigatório")
+		} else if names[tag.Name] {
+			v.add(ptr+"/name", CodeDuplicate, "tag %q duplicada", tag.Name)
+		}
+		names[tag.Name] = true
+		v.externalDocs(ptr+"/externalDocs", tag.ExternalDocs)
+	}
+	v.externalDocs("/externalDocs", d.ExternalDocs)
+}
+
+func (v *docValidator) uri(ptr, s string) {
+	if _, err := url.Parse(s); err != nil {
+		v.add(ptr, CodeInvalidFormat, "URI inválida: %v", err)
+	}
+}
+
+func (v *docValidator) requiredURI(ptr, s string) {
+	if s == "" {
+		v.add(ptr, CodeRequired, "campo obrigatório")
+		return
+	}
+	v.uri(ptr, s)
+}
+
+func (v *docValidator) info(ptr string, info *Info) {
+	if info.Title == "" {
+		v.add(ptr+"/title", CodeRequired, "campo obrigatório")
+	}
+	if info.Version == "" {
+		v.add(ptr+"/version", CodeRequired, "campo obrigatório")
+	}
+	if info.TermsOfService != nil {
+		v.uri(ptr+"/termsOfService", *info.TermsOfService)
+	}
+	if c := info.Contact; c != nil {
+		if c.URL != nil {
+			v.uri(ptr+"/contact/url", *c.URL)
+		}
+		if c.Email != nil {
+			if _, err := mail.ParseAddress(*c.Email); err != nil {
+				v.add(ptr+"/contact/email", CodeInvalidFormat, "e-mail inválido %q", *c.Email)
+			}
+		}
+	}
+	if l := info.License; l != nil {
+		if l.Name == "" {
+			v.add(ptr+"/license/name", CodeRequired, "campo obrigatório")
+		}
+		if l.ID != nil && l.URL != nil {
+			v.add(ptr+"/license", CodeMutuallyExclusive, "identifier e url são mutuamente exclusivos")
+		}
+		if l.URL != nil {
+			v.uri(ptr+"/license/url", *l.URL)
+		}
+	}
+}
+
+func (v *docValidator) server(ptr string, s *Server) {
+	if s.URL == "" {
+		v.add(ptr+"/url", CodeRequired, "campo obrigatório")
+	}
+	for _, m := range reTemplateVar.FindAllStringSubmatch(s.URL, -1) {
+		if _, ok := s.Variables[m[1]]; !ok {
+			v.add(ptr+"/url", CodeUndeclared, "variável {%s} não declarada em variables", m[1])
+		}
+	}
+	for _, name := range sortedKeys(s.Variables) {
+		sv := s.Variables[name]
+		vptr := ptrJoin(ptr, "variables", name)
+		if sv.Enum != nil && len(sv.Enum) == 0 {
+			v.add(vptr+"/enum", CodeInvalidValue, "enum não pode ser vazio")
+		}
+		if len(sv.Enum) > 0 && !contains(sv.Enum, sv.Default) {
+			v.add(vptr+"/default", CodeInvalidValue, "default %q não está em enum", sv.Default)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *docValidator) externalDocs(ptr string, e *ExternalDocumentation) {
+	if e != nil {
+		v.requiredURI(ptr+"/url", e.URL)
+	}
+}
+
+// checkRef confere um $ref (se houver) e informa se o valor era um $ref.
+func checkRef[T refable](v *docValidator, ptr string, x T) bool {
+	ref := x.reference()
+	if ref == nil {
+		return false
+	}
+	if _, err := resolveRef(v.r, x); err != nil && !errors.Is(err, ErrExternalRef) {
+		v.add(ptr, CodeInvalidRef, "%v", err)
+		v.errs[len(v.errs)-1].Err = err
+	}
+	return true
+}
+
+func (v *docValidator) pathItemOrRef(ptr string, p PathItemOrRef) {
+	if checkRef(v, ptr, p) {
+		return
+	}
+	if p.PathItem == nil {
+		v.add(ptr, CodeRequired, "path item vazio")
+		return
+	}
+	v.pathItem(ptr, p.PathItem)
+}
+
+func (v *docValidator) pathItem(ptr string, pi *PathItem) {
+	for _, o := range pathItemOperations(pi) {
+		v.operation(ptrJoin(ptr, o.method), o.op)
+	}
+	for i := range pi.Servers {
+		v.server(ptrJoin(ptr, "servers", fmt.Sprint(i)), &pi.Servers[i])
+	}
+	for i, p := range pi.Parameters {
+		v.parameterOrRef(ptrJoin(ptr, "parameters", fmt.Sprint(i)), p)
+	}
+	v.uniqueParameters(ptr, pi.Parameters)
+}
+
+type methodOperation struct {
+	method string
+	op     *Operation
+}
+
+// pathItemOperations lista as operações definidas, na ordem da especificação.
+func pathItemOperations(pi *PathItem) []methodOperation {
+	all := []methodOperation{
+		{"get", pi.Get}, {"put", pi.Put}, {"post", pi.Post}, {"delete", pi.Delete},
+		{"options", pi.Options}, {"head", pi.Head}, {"patch", pi.Patch}, {"trace", pi.Trace},
+	}
+	ops := all[:0]
+	for _, o := range all {
+		if o.op != nil {
+			ops = append(ops, o)
+		}
+	}
+	return ops
+}
+
+func (v *docValidator) operation(ptr string, op *Operation) {
+	v.externalDocs(ptr+"/externalDocs", op.ExternalDocs)
+	for i, p := range op.Parameters {
+		v.parameterOrRef(ptrJoin(ptr, "parameters", fmt.Sprint(i)), p)
+	}
+	v.uniqueParameters(ptr, op.Parameters)
+	if op.RequestBody != nil {
+		v.requestBodyOrRef(ptr+"/requestBody", *op.RequestBody)
+	}
+	if err := op.ValidateRequiredResponses(); err != nil {
+		v.add(ptr+"/responses", CodeRequired, "%v", err)
+	}
+	for _, code := range sortedKeys(op.Responses) {
+		rptr := ptrJoin(ptr, "responses", code)
+		if code != "default" && !reStatusCode.MatchString(code) {
+			v.add(rptr, CodeInvalidName, "código de status inválido %q", code)
+		}
+		v.responseOrRef(rptr, op.Responses[code])
+	}
+	for _, name := range sortedKeys(op.Callbacks) {
+		v.callbackOrRef(ptrJoin(ptr, "callbacks", name), op.Callbacks[name])
+	}
+	v.security(ptr+"/security", op.Security)
+	for i := range op.Servers {
+		v.server(ptrJoin(ptr, "servers", fmt.Sprint(i)), &op.Servers[i])
+	}
+}
+
+var parameterStyles = map[ParameterIn][]ParameterStyle{
+	InPath:   {StyleMatrix, StyleLabel, StyleSimple},
+	InQuery:  {StyleForm, StyleSpaceDelimited, StylePipeDelimited, StyleDeepObject},
+	InHeader: {StyleSimple},
+	InCookie: {StyleForm},
+}
+
+func (v *docValidator) parameterOrRef(ptr string, p ParameterOrRef) {
+	if checkRef(v, ptr, p) || p.Param == nil {
+		return
+	}
+	v.parameter(ptr, p.Param)
+}
+
+func (v *docValidator) parameter(ptr string, p *Parameter) {
+	if p.Name == "" {
+		v.add(ptr+"/name", CodeRequired, "campo obrigatório")
+	}
+	styles, known := parameterStyles[p.In]
+	switch {
+	case p.In == "":
+		v.add(ptr+"/in", CodeRequired, "campo obrigatório")
+	case !known:
+		v.add(ptr+"/in", CodeInvalidValue, "valor %q inválido (query, header, path ou cookie)", p.In)
+	}
+	if p.In == InPath && (p.Required == nil || !*p.Required) {
+		v.add(ptr+"/required", CodeInvalidValue, "parâmetro de path deve ter required: true")
+	}
+	if p.AllowEmptyValue != nil && p.In != InQuery {
+		v.add(ptr+"/allowEmptyValue", CodeNotAllowed, "permitido apenas em parâmetros de query")
+	}
+	if p.Style != nil && known {
+		ok := false
+		for _, s := range styles {
+			ok = ok || s == *p.Style
+		}
+		if !ok {
+			v.add(ptr+"/style", CodeNotAllowed, "style %q não se aplica a parâmetros em %s", *p.Style, p.In)
+		}
+	}
+	v.schemaXorContent(ptr, p.Schema, p.Content)
+	v.exampleXorExamples(ptr, p.Example, p.Examples)
+}
+
+func (v *docValidator) schemaXorContent(ptr string, schema *SchemaOrRef, content map[string]MediaType) {
+	switch {
+	case schema != nil && content != nil:
+		v.add(ptr, CodeMutuallyExclusive, "schema e content são mutuamente exclusivos")
+	case schema == nil && content == nil:
+		v.add(ptr, CodeRequired, "schema ou content é obrigatório")
+	case content != nil && len(content) != 1:
+		v.add(ptr+"/content", CodeInvalidValue, "content deve ter exatamente uma entrada")
+	}
+	if schema != nil {
+		v.schemaOrRef(ptr+"/schema", *schema)
+	}
+	v.content(ptr+"/content", content)
+}
+
+func (v *docValidator) exampleXorExamples(ptr string, example any, examples map[string]ExampleOrRef) {
+	if example != nil && examples != nil {
+		v.add(ptr, CodeMutuallyExclusive, "example e examples são mutuamente exclusivos")
+	}
+	for _, name := range sortedKeys(examples) {
+		v.exampleOrRef(ptrJoin(ptr, "examples", name), examples[name])
+	}
+}
+
+func (v *docValidator) exampleOrRef(ptr string, e ExampleOrRef) {
+	if checkRef(v, ptr, e) || e.Example == nil {
+		return
+	}
+	if e.Example.Value != nil && e.Example.ExternalValue != nil {
+		v.add(ptr, CodeMutuallyExclusive, "value e externalValue são mutuamente exclusivos")
+	}
+}
+
+func (v *docValidator) content(ptr string, content map[string]MediaType) {
+	for _, mt := range sortedKeys(content) {
+		m := content[mt]
+		mptr := ptrJoin(ptr, mt)
+		if m.Schema != nil {
+			v.schemaOrRef(mptr+"/schema", *m.Schema)
+		}
+		v.exampleXorExamples(mptr, m.Example, m.Examples)
+		for _, name := range sortedKeys(m.Encoding) {
+			eptr := ptrJoin(mptr, "encoding", name)
+			for _, h := range sortedKeys(m.Encoding[name].Headers) {
+				v.headerOrRef(ptrJoin(eptr, "headers", h), m.Encoding[name].Headers[h])
+			}
+		}
+	}
+}
+
+func (v *docValidator) headerOrRef(ptr string, h HeaderOrRef) {
+	if checkRef(v, ptr, h) || h.Header == nil {
+		return
+	}
+	if h.Header.Style != nil && *h.Header.Style != StyleSimple {
+		v.add(ptr+"/style", CodeNotAllowed, "headers só admitem style simple")
+	}
+	v.schemaXorContent(ptr, h.Header.Schema, h.Header.Content)
+	v.exampleXorExamples(ptr, h.Header.Example, h.Header.Examples)
+}
+
+func (v *docValidator) responseOrRef(ptr string, r ResponseOrRef) {
+	if checkRef(v, ptr, r) || r.Resp == nil {
+		return
+	}
+	if r.Resp.Description == "" {
+		v.add(ptr+"/description", CodeRequired, "campo obrigatório")
+	}
+	for _, name := range sortedKeys(r.Resp.Headers) {
+		v.headerOrRef(ptrJoin(ptr, "headers", name), r.Resp.Headers[name])
+	}
+	v.content(ptr+"/content", r.Resp.Content)
+	for _, name := range sortedKeys(r.Resp.Links) {
+		v.linkOrRef(ptrJoin(ptr, "links", name), r.Resp.Links[name])
+	}
+}
+
+func (v *docValidator) linkOrRef(ptr string, l LinkOrRef) {
+	if checkRef(v, ptr, l) || l.Link == nil {
+		return
+	}
+	if (l.Link.OperationRef == nil) == (l.Link.OperationID == nil) {
+		v.add(ptr, CodeMutuallyExclusive, "exatamente um entre operationRef e operationId é obrigatório")
+	}
+	if l.Link.Server != nil {
+		v.server(ptr+"/server", l.Link.Server)
+	}
+}
+
+func (v *docValidator) callbackOrRef(ptr string, c CallbackOrRef) {
+	if checkRef(v, ptr, c) || c.Callback == nil {
+		return
+	}
+	for _, expr := range sortedKeys(*c.Callback) {
+		v.pathItemOrRef(ptrJoin(ptr, expr), (*c.Callback)[expr])
+	}
+}
+
+func (v *docValidator) requestBodyOrRef(ptr string, rb RequestBodyOrRef) {
+	if checkRef(v, ptr, rb) || rb.Body == nil {
+		return
+	}
+	if len(rb.Body.Content) == 0 {
+		v.add(ptr+"/content", CodeRequired, "campo obrigatório")
+	}
+	v.content(ptr+"/content", rb.Body.Content)
+}
+
+func (v *docValidator) security(ptr string, reqs []SecurityRequirement) {
+	for i, req := range reqs {
+		for _, name := range sortedKeys(req) {
+			if v.doc.Components == nil || v.doc.Components.SecuritySchemes == nil {
+				v.add(ptrJoin(ptr, fmt.Sprint(i), name), CodeUndeclared, "security scheme %q não declarado em components", name)
+				continue
+			}
+			if _, ok := v.doc.Components.SecuritySchemes[name]; !ok {
+				v.add(ptrJoin(ptr, fmt.Sprint(i), name), CodeUndeclared, "security scheme %q não declarado em components", name)
+			}
+		}
+	}
+}
+
+func (v *docValidator) securitySchemeOrRef(ptr string, s SecuritySchemeOrRef) {
+	if checkRef(v, ptr, s) || s.Scheme == nil {
+		return
+	}
+	sc := s.Scheme
+	switch sc.Type {
+	case "":
+		v.add(ptr+"/type", CodeRequired, "campo obrigatório")
+	case SecAPIKey:
+		if sc.Name == nil || *sc.Name == "" {
+			v.add(ptr+"/name", CodeRequired, "obrigatório para apiKey")
+		}
+		if sc.In != InQuery && sc.In != InHeader && sc.In != InCookie {
+			v.add(ptr+"/in", CodeInvalidValue, "apiKey exige in query, header ou cookie")
+		}
+	case SecHTTP:
+		if sc.Scheme == nil || *sc.Scheme == "" {
+			v.add(ptr+"/scheme", CodeRequired, "obrigatório para http")
+		}
+	case SecOAuth2:
+		if sc.Flows == nil {
+			v.add(ptr+"/flows", CodeRequired, "obrigatório para oauth2")
+		} else {
+			v.oauthFlows(ptr+"/flows", sc.Flows)
+		}
+	case SecOpenIDConnect:
+		if sc.OpenIDConnectURL == nil {
+			v.add(ptr+"/openIdConnectUrl", CodeRequired, "obrigatório para openIdConnect")
+		} else {
+			v.requiredURI(ptr+"/openIdConnectUrl", *sc.OpenIDConnectURL)
+		}
+	case SecMutualTLS:
+	default:
+		v.add(ptr+"/type", CodeInvalidValue, "tipo %q inválido", sc.Type)
+	}
+}
+
+func (v *docValidator) oauthFlows(ptr string, f *OAuthFlows) {
+	flows := []struct {
+		name          string
+		flow          *OAuthFlow
+		authorization bool
+		token         bool
+	}{
+		{"implicit", f.Implicit, true, false},
+		{"password", f.Password, false, true},
+		{"clientCredentials", f.ClientCredentials, false, true},
+		{"authorizationCode", f.AuthorizationCode, true, true},
+	}
+	for _, fl := range flows {
+		if fl.flow == nil {
+			continue
+		}
+		fptr := ptrJoin(ptr, fl.name)
+		if fl.authorization {
+			v.requiredURI(fptr+"/authorizationUrl", fl.flow.AuthorizationURL)
+		} else if fl.flow.AuthorizationURL != "" {
+			v.add(fptr+"/authorizationUrl", CodeNotAllowed, "não se aplica ao fluxo %s", fl.name)
+		}
+		if fl.token {
+			v.requiredURI(fptr+"/tokenUrl", fl.flow.TokenURL)
+		} else if fl.flow.TokenURL != "" {
+			v.add(fptr+"/tokenUrl", CodeNotAllowed, "não se aplica ao fluxo %s", fl.name)
+		}
+		if fl.flow.RefreshURL != nil {
+			v.uri(fptr+"/refreshUrl", *fl.flow.RefreshURL)
+		}
+		if fl.flow.Scopes == nil {
+			v.add(fptr+"/scopes", CodeRequired, "campo obrigatório")
+		}
+	}
+}
+
+func (v *docValidator) components(ptr string, c *Components) {
+	if c == nil {
+		return
+	}
+	keys := func(kind string, names []string) {
+		for _, name := range names {
+			if !reComponentKey.MatchString(name) {
+				v.add(ptrJoin(ptr, kind, name), CodeInvalidName, "nome de componente inválido (use [a-zA-Z0-9.-_])")
+			}
+		}
+	}
+	keys("schemas", sortedKeys(c.Schemas))
+	for _, name := range sortedKeys(c.Schemas) {
+		v.schemaOrRef(ptrJoin(ptr, "schemas", name), c.Schemas[name])
+	}
+	keys("responses", sortedKeys(c.Responses))
+	for _, name := range sortedKeys(c.Responses) {
+		v.responseOrRef(ptrJoin(ptr, "responses", name), c.Responses[name])
+	}
+	keys("parameters", sortedKeys(c.Parameters))
+	for _, name := range sortedKeys(c.Parameters) {
+		v.parameterOrRef(ptrJoin(ptr, "parameters", name), c.Parameters[name])
+	}
+	keys("examples", sortedKeys(c.Examples))
+	for _, name := range sortedKeys(c.Examples) {
+		v.exampleOrRef(ptrJoin(ptr, "examples", name), c.Examples[name])
+	}
+	keys("requestBodies", sortedKeys(c.RequestBodies))
+	for _, name := range sortedKeys(c.RequestBodies) {
+		v.requestBodyOrRef(ptrJoin(ptr, "requestBodies", name), c.RequestBodies[name])
+	}
+	keys("headers", sortedKeys(c.Headers))
+	for _, name := range sortedKeys(c.Headers) {
+		v.headerOrRef(ptrJoin(ptr, "headers", name), c.Headers[name])
+	}
+	keys("securitySchemes", sortedKeys(c.SecuritySchemes))
+	for _, name := range sortedKeys(c.SecuritySchemes) {
+		v.securitySchemeOrRef(ptrJoin(ptr, "securitySchemes", name), c.SecuritySchemes[name])
+	}
+	keys("links", sortedKeys(c.Links))
+	for _, name := range sortedKeys(c.Links) {
+		v.linkOrRef(ptrJoin(ptr, "links", name), c.Links[name])
+	}
+	keys("callbacks", sortedKeys(c.Callbacks))
+	for _, name := range sortedKeys(c.Callbacks) {
+		v.callbackOrRef(ptrJoin(ptr, "callbacks", name), c.Callbacks[name])
+	}
+	keys("pathItems", sortedKeys(c.PathItems))
+	for _, name := range sortedKeys(c.PathItems) {
+		v.pathItemOrRef(ptrJoin(ptr, "pathItems", name), c.PathItems[name])
+	}
+}
+
+var schemaTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true,
+	"number": true, "string": true, "integer": true,
+}
+
+func (v *docValidator) schemaOrRef(ptr string, s SchemaOrRef) {
+	// os keywords ao lado de um $ref também são conferidos
+	checkRef(v, ptr, s)
+	if s.Schema == nil {
+		return
+	}
+	v.schema(ptr, s.Schema)
+}
+
+func (v *docValidator) schema(ptr string, s *Schema) {
+	if s.Type != nil {
+		types := s.Type.Many
+		if s.Type.One != nil {
+			types = []string{*s.Type.One}
+		}
+		seen := map[string]bool{}
+		for _, t := range types {
+			if !schemaTypes[t] {
+				v.add(ptr+"/type", CodeInvalidValue, "tipo %q inválido", t)
+			}
+			if seen[t] {
+				v.add(ptr+"/type", CodeDuplicate, "tipo %q repetido", t)
+			}
+			seen[t] = true
+		}
+	}
+	if s.Enum != nil && len(s.Enum) == 0 {
+		v.add(ptr+"/enum", CodeInvalidValue, "enum não pode ser vazio")
+	}
+	seen := map[string]bool{}
+	for _, name := range s.Required {
+		if seen[name] {
+			v.add(ptr+"/required", CodeDuplicate, "propriedade %q repetida", name)
+		}
+		seen[name] = true
+	}
+	for _, name := range sortedKeys(s.DependentRequired) {
+		seen := map[string]bool{}
+		for _, dep := range s.DependentRequired[name] {
+			if seen[dep] {
+				v.add(ptrJoin(ptr, "dependentRequired", name), CodeDuplicate, "propriedade %q repetida", dep)
+			}
+			seen[dep] = true
+		}
+	}
+	anchors := []struct {
+		name  string
+		value *string
+	}{
+		{"$anchor", s.Anchor}, {"$dynamicAnchor", s.DynamicAnchor},
+	}
+	for _, a := range anchors {
+		if a.value != nil && !reAnchor.MatchString(*a.value) {
+			v.add(ptrJoin(ptr, a.name), CodeInvalidName, "âncora %q inválida", *a.value)
+		}
+	}
+	nonNegative := []struct {
+		name  string
+		value *int
+	}{
+		{"minProperties", s.MinProperties}, {"maxProperties", s.MaxProperties},
+		{"minItems", s.MinItems}, {"maxItems", s.MaxItems},
+		{"minContains", s.MinContains}, {"maxContains", s.MaxContains},
+		{"minLength", s.MinLength}, {"maxLength", s.MaxLength},
+	}
+	for _, n := range nonNegative {
+		if n.value != nil && *n.value < 0 {
+			v.add(ptrJoin(ptr, n.name), CodeInvalidValue, "deve ser >= 0")
+		}
+	}
+	intRanges := []struct {
+		min, max string
+		lo, hi   *int
+	}{
+		{"minProperties", "maxProperties", s.MinProperties, s.MaxProperties},
+		{"minItems", "maxItems", s.MinItems, s.MaxItems},
+		{"minContains", "maxContains", s.MinContains, s.MaxContains},
+		{"minLength", "maxLength", s.MinLength, s.MaxLength},
+	}
+	for _, r := range intRanges {
+		if r.lo != nil && r.hi != nil && *r.lo > *r.hi {
+			v.add(ptrJoin(ptr, r.min), CodeInvalidValue, "%s maior que %s", r.min, r.max)
+		}
+	}
+	if s.Minimum != nil && s.Maximum != nil && *s.Minimum > *s.Maximum {
+		v.add(ptr+"/minimum", CodeInvalidValue, "minimum maior que maximum")
+	}
+	if s.MultipleOf != nil && *s.MultipleOf <= 0 {
+		v.add(ptr+"/multipleOf", CodeInvalidValue, "deve ser > 0")
+	}
+	if s.Pattern != nil {
+		v.pattern(ptr+"/pattern", *s.Pattern)
+	}
+	for _, name := range sortedKeys(s.PatternProperties) {
+		v.pattern(ptrJoin(ptr, "patternProperties", name), name)
+	}
+	if s.Discriminator != nil && s.Discriminator.PropertyName == "" {
+		v.add(ptr+"/discriminator/propertyName", CodeRequired, "campo obrigatório")
+	}
+	eachSubschema(ptr, s, func(ptr string, sub *SchemaOrRef) error {
+		v.schemaOrRef(ptr, *sub)
+		return nil
+	})
+}
+
+// brokenRegex são os erros do RE2 que também tornam a regex inválida em
+// ECMA-262; os demais (lookahead, backreference, ...) são recursos que o Go
+// não implementa.
+var brokenRegex = map[syntax.ErrorCode]bool{
+	syntax.ErrMissingParen:          true,
+	syntax.ErrUnexpectedParen:       true,
+	syntax.ErrMissingBracket:        true,
+	syntax.ErrTrailingBackslash:     true,
+	syntax.ErrMissingRepeatArgument: true,
+	syntax.ErrInvalidRepeatOp:       true,
+	syntax.ErrInvalidCharRange:      true,
+}
+
+// regexError compila expr com o RE2 do Go. broken indica que a regex é
+// inválida também em ECMA-262; sem broken, um err não nil é um recurso que o
+// RE2 não implementa.
+func regexError(expr string) (broken bool, err error) {
+	if _, err = regexp.Compile(expr); err == nil {
+		return false, nil
+	}
+	var syntaxErr *syntax.Error
+	return !errors.As(err, &syntaxErr) || brokenRegex[syntaxErr.Code], err
+}
+
+// pattern confere uma regex ECMA-262 com o RE2 do Go: só as que são
+// inválidas nos dois viram erro; as que o RE2 não suporta viram aviso.
+func (v *docValidator) pattern(ptr, expr string) {
+	switch broken, err := regexError(expr); {
+	case err == nil:
+	case broken:
+		v.add(ptr, CodeInvalidFormat, "regex inválida: %v", err)
+	default:
+		v.warn(ptr, CodeInvalidFormat, "regex não suportada pelo Go: %v", err)
+	}
+}
@@ -0,0 +1,356 @@
+package oas
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ========== Percurso dos "OrRef" de um documento ==========
+
+// refSite é um ponto do documento que pode conter um $ref.
+type refSite interface {
+	refable
+	json.Unmarshaler
+	// component é o mapa de Components onde esse tipo é declarado.
+	component() string
+}
+
+func (s *SchemaOrRef) component() string         { return "schemas" }
+func (p *PathItemOrRef) component() string       { return "pathItems" }
+func (p *ParameterOrRef) component() string      { return "parameters" }
+func (r *RequestBodyOrRef) component() string    { return "requestBodies" }
+func (r *ResponseOrRef) component() string       { return "responses" }
+func (h *HeaderOrRef) component() string         { return "headers" }
+func (e *ExampleOrRef) component() string        { return "examples" }
+func (l *LinkOrRef) component() string           { return "links" }
+func (c *CallbackOrRef) component() string       { return "callbacks" }
+func (s *SecuritySchemeOrRef) component() string { return "securitySchemes" }
+
+// replaceSite troca o conteúdo de um refSite pelo nó decodificado.
+func replaceSite(site refSite, node any) error {
+	v := reflect.ValueOf(site).Elem()
+	v.Set(reflect.Zero(v.Type()))
+	return decodeNode(node, site)
+}
+
+// newSite cria um refSite vazio do mesmo tipo de site.
+func newSite(site refSite) refSite {
+	return reflect.New(reflect.TypeOf(site).Elem()).Interface().(refSite)
+}
+
+// refWalker percorre, em ordem determinística, todos os "OrRef" alcançáveis
+// de um Document, montando o JSON Pointer de cada um. visit é chamado antes
+// de descer no valor (e pode substituí-lo); leave, se definido, depois.
+type refWalker struct {
+	visit func(ptr string, site refSite) error
+	leave func(ptr string, site refSite)
+}
+
+func ptrJoin(ptr string, tokens ...string) string {
+	for _, t := range tokens {
+		ptr += "/" + escapePointerToken(t)
+	}
+	return ptr
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// walkMap percorre um mapa de "OrRef" gravando de volta cada valor visitado.
+func walkMap[T any, P interface {
+	*T
+	refSite
+}](w *refWalker, ptr string, m map[string]T) error {
+	for _, k := range sortedKeys(m) {
+		v := m[k]
+		err := w.site(ptrJoin(ptr, k), P(&v))
+		m[k] = v
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkSlice[T any, P interface {
+	*T
+	refSite
+}](w *refWalker, ptr string, s []T) error {
+	for i := range s {
+		if err := w.site(ptrJoin(ptr, strconv.Itoa(i)), P(&s[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *refWalker) document(d *Document) error {
+	if err := walkMap(w, "/paths", d.Paths); err != nil {
+		return err
+	}
+	if err := walkMap(w, "/webhooks", d.Webhooks); err != nil {
+		return err
+	}
+	return w.components("/components", d.Components)
+}
+
+func (w *refWalker) components(ptr string, c *Components) error {
+	if c == nil {
+		return nil
+	}
+	steps := []func() error{
+		func() error { return walkMap(w, ptr+"/schemas", c.Schemas) },
+		func() error { return walkMap(w, ptr+"/responses", c.Responses) },
+		func() error { return walkMap(w, ptr+"/parameters", c.Parameters) },
+		func() error { return walkMap(w, ptr+"/examples", c.Examples) },
+		func() error { return walkMap(w, ptr+"/requestBodies", c.RequestBodies) },
+		func() error { return walkMap(w, ptr+"/headers", c.Headers) },
+		func() error { return walkMap(w, ptr+"/securitySchemes", c.SecuritySchemes) },
+		func() error { return walkMap(w, ptr+"/links", c.Links) },
+		func() error { return walkMap(w, ptr+"/callbacks", c.Callbacks) },
+		func() error { return walkMap(w, ptr+"/pathItems", c.PathItems) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// site visita um "OrRef" e desce no seu valor.
+func (w *refWalker) site(ptr string, s refSite) error {
+	if w.visit != nil {
+		if err := w.visit(ptr, s); err != nil {
+			return err
+		}
+	}
+	if err := w.value(ptr, s); err != nil {
+		return err
+	}
+	if w.leave != nil {
+		w.leave(ptr, s)
+	}
+	return nil
+}
+
+func (w *refWalker) value(ptr string, s refSite) error {
+	switch v := s.(type) {
+	case *SchemaOrRef:
+		return w.schema(ptr, v.Schema)
+	case *PathItemOrRef:
+		return w.pathItem(ptr, v.PathItem)
+	case *ParameterOrRef:
+		return w.parameter(ptr, v.Param)
+	case *RequestBodyOrRef:
+		if v.Body != nil {
+			return w.content(ptr+"/content", v.Body.Content)
+		}
+	case *ResponseOrRef:
+		return w.response(ptr, v.Resp)
+	case *HeaderOrRef:
+		return w.header(ptr, v.Header)
+	case *CallbackOrRef:
+		if v.Callback != nil {
+			return walkMap(w, ptr, *v.Callback)
+		}
+	}
+	return nil
+}
+
+func (w *refWalker) pathItem(ptr string, pi *PathItem) error {
+	if pi == nil {
+		return nil
+	}
+	ops := []struct {
+		name string
+		op   *Operation
+	}{
+		{"get", pi.Get}, {"put", pi.Put}, {"post", pi.Post}, {"delete", pi.Delete},
+		{"options", pi.Options}, {"head", pi.Head}, {"patch", pi.Patch}, {"trace", pi.Trace},
+	}
+	for _, o := range ops {
+		if err := w.operation(ptrJoin(ptr, o.name), o.op); err != nil {
+			return err
+		}
+	}
+	return walkSlice(w, ptr+"/parameters", pi.Parameters)
+}
+
+func (w *refWalker) operation(ptr string, op *Operation) error {
+	if op == nil {
+		return nil
+	}
+	if err := walkSlice(w, ptr+"/parameters", op.Parameters); err != nil {
+		return err
+	}
+	if op.RequestBody != nil {
+		if err := w.site(ptr+"/requestBody", op.RequestBody); err != nil {
+			return err
+		}
+	}
+	if err := walkMap(w, ptr+"/responses", op.Responses); err != nil {
+		return err
+	}
+	return walkMap(w, ptr+"/callbacks", op.Callbacks)
+}
+
+func (w *refWalker) parameter(ptr string, p *Parameter) error {
+	if p == nil {
+		return nil
+	}
+	if p.Schema != nil {
+		if err := w.site(ptr+"/schema", p.Schema); err != nil {
+			return err
+		}
+	}
+	if err := walkMap(w, ptr+"/examples", p.Examples); err != nil {
+		return err
+	}
+	return w.content(ptr+"/content", p.Content)
+}
+
+func (w *refWalker) header(ptr string, h *Header) error {
+	if h == nil {
+		return nil
+	}
+	if h.Schema != nil {
+		if err := w.site(ptr+"/schema", h.Schema); err != nil {
+			return err
+		}
+	}
+	if err := walkMap(w, ptr+"/examples", h.Examples); err != nil {
+		return err
+	}
+	return w.content(ptr+"/content", h.Content)
+}
+
+func (w *refWalker) response(ptr string, r *Response) error {
+	if r == nil {
+		return nil
+	}
+	if err := walkMap(w, ptr+"/headers", r.Headers); err != nil {
+		return err
+	}
+	if err := w.content(ptr+"/content", r.Content); err != nil {
+		return err
+	}
+	return walkMap(w, ptr+"/links", r.Links)
+}
+
+func (w *refWalker) content(ptr string, content map[string]MediaType) error {
+	for _, k := range sortedKeys(content) {
+		mt := content[k]
+		mptr := ptrJoin(ptr, k)
+		if mt.Schema != nil {
+			if err := w.site(mptr+"/schema", mt.Schema); err != nil {
+				return err
+			}
+		}
+		if err := walkMap(w, mptr+"/examples", mt.Examples); err != nil {
+			return err
+		}
+		for _, ek := range sortedKeys(mt.Encoding) {
+			if err := walkMap(w, ptrJoin(mptr, "encoding", ek, "headers"), mt.Encoding[ek].Headers); err != nil {
+				return err
+			}
+		}
+		content[k] = mt
+	}
+	return nil
+}
+
+func (w *refWalker) schema(ptr string, s *Schema) error {
+	if s == nil {
+		return nil
+	}
+	return eachSubschema(ptr, s, func(ptr string, sub *SchemaOrRef) error {
+		return w.site(ptr, sub)
+	})
+}
+
+// eachSubschema chama fn para cada schema aninhado em s, com seu JSON
+// Pointer, parando no primeiro erro. O que fn altera em sub é gravado de
+// volta, inclusive nos mapas (properties, $defs, ...). É o único percurso dos
+// subschemas, usado por refWalker e pela validação.
+func eachSubschema(ptr string, s *Schema, fn func(ptr string, sub *SchemaOrRef) error) error {
+	lists := []struct {
+		name string
+		list []SchemaOrRef
+	}{
+		{"allOf", s.AllOf}, {"oneOf", s.OneOf}, {"anyOf", s.AnyOf}, {"not", s.Not}, {"prefixItems", s.PrefixItems},
+	}
+	for _, l := range lists {
+		for i := range l.list {
+			if err := fn(ptrJoin(ptr, l.name, strconv.Itoa(i)), &l.list[i]); err != nil {
+				return err
+			}
+		}
+	}
+	maps := []struct {
+		name string
+		m    map[string]SchemaOrRef
+	}{
+		{"properties", s.Properties}, {"patternProperties", s.PatternProperties}, {"$defs", s.Defs}, {"dependentSchemas", s.DependentSchemas},
+	}
+	for _, m := range maps {
+		for _, k := range sortedKeys(m.m) {
+			sub := m.m[k]
+			err := fn(ptrJoin(ptr, m.name, k), &sub)
+			if sub != m.m[k] {
+				m.m[k] = sub // só grava se mudou: ler não escreve no documento
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	boolOrSchema := []struct {
+		name string
+		ap   *AdditionalProperties
+	}{
+		{"additionalProperties", s.AdditionalProperties}, {"unevaluatedProperties", s.UnevaluatedProperties}, {"unevaluatedItems", s.UnevaluatedItems},
+	}
+	for _, b := range boolOrSchema {
+		if b.ap != nil && b.ap.Schema != nil {
+			if err := fn(ptrJoin(ptr, b.name), b.ap.Schema); err != nil {
+				return err
+			}
+		}
+	}
+	if s.Items != nil {
+		if s.Items.Single != nil {
+			if err := fn(ptr+"/items", s.Items.Single); err != nil {
+				return err
+			}
+		}
+		for i := range s.Items.List {
+			if err := fn(ptrJoin(ptr, "items", strconv.Itoa(i)), &s.Items.List[i]); err != nil {
+				return err
+			}
+		}
+	}
+	singles := []struct {
+		name string
+		s    *SchemaOrRef
+	}{
+		{"contains", s.Contains}, {"if", s.If}, {"then", s.Then}, {"else", s.Else},
+		{"propertyNames", s.PropertyNames}, {"contentSchema", s.ContentSchema},
+	}
+	for _, single := range singles {
+		if single.s != nil {
+			if err := fn(ptrJoin(ptr, single.name), single.s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ========== YAML ==========
+//
+// O caminho YAML reaproveita integralmente os (Un)MarshalJSON dos tipos union
+// (SchemaOrRef, StringOrArray, AdditionalProperties, ...): o YAML é convertido
+// para JSON preservando a ordem das chaves e só então decodificado, e a saída
+// é o JSON do documento re-emitido como YAML na mesma ordem.
+
+// LoadYAML decodifica um documento OpenAPI em YAML. Diferente de Load, não
+// confere a versão.
+func LoadYAML(b []byte, opts ...LoadOption) (*Document, error) {
+	raw, err := yamlToJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	cfg := newLoadConfig(opts)
+	var sm SourceMap
+	if cfg.sourceMap {
+		if sm, err = yamlSourceMap(b, ""); err != nil {
+			return nil, err
+		}
+	}
+	return unmarshalDocument(raw, sm, cfg.strict)
+}
+
+// YAML serializa o documento em YAML, com as chaves na mesma ordem do JSON.
+func (d *Document) YAML() ([]byte, error) {
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYAML(raw)
+}
+
+// yamlToJSON converte um YAML (um único documento) em JSON equivalente.
+func yamlToJSON(b []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := newYAMLExpander().writeJSON(&buf, &root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Limites de expansão de aliases, os mesmos do decoder do yaml.v3: até
+// yamlAliasLow nós tudo é aceito; daí em diante a fração de nós vindos de
+// aliases cai linearmente de 99% até 10% em yamlAliasHigh nós.
+const (
+	yamlAliasLow  = 400000
+	yamlAliasHigh = 4000000
+)
+
+// errYAMLAliasing é devolvido quando aliases expandem o documento muito além
+// do tamanho do arquivo (ex.: "billion laughs").
+var errYAMLAliasing = errors.New("yaml: documento com aliases em excesso")
+
+// yamlExpander percorre um yaml.Node expandindo aliases e merge keys. Aliases
+// cíclicos (um anchor usado dentro dele mesmo) são rejeitados, e a quantidade
+// de nós expandidos é limitada como no decoder do yaml.v3.
+type yamlExpander struct {
+	active     map[*yaml.Node]bool // anchors em expansão
+	nodes      int                 // nós visitados
+	aliasNodes int                 // nós visitados dentro de um alias
+	aliasDepth int
+}
+
+func newYAMLExpander() *yamlExpander {
+	return &yamlExpander{active: map[*yaml.Node]bool{}}
+}
+
+// visit resolve n (se for alias), contabiliza o nó e chama fn com ele.
+func (e *yamlExpander) visit(n *yaml.Node, fn func(*yaml.Node) error) error {
+	if n.Kind == yaml.AliasNode {
+		if e.active[n.Alias] {
+			return fmt.Errorf("yaml: alias %q cíclico na linha %d", n.Value, n.Line)
+		}
+		e.aliasDepth++
+		defer func() { e.aliasDepth-- }()
+		n = n.Alias
+	}
+	e.nodes++
+	if e.aliasDepth > 0 {
+		e.aliasNodes++
+	}
+	if e.excessive() {
+		return errYAMLAliasing
+	}
+	if n.Anchor != "" {
+		e.active[n] = true
+		defer delete(e.active, n)
+	}
+	return fn(n)
+}
+
+func (e *yamlExpander) excessive() bool {
+	if e.aliasNodes <= 100 || e.nodes <= yamlAliasLow {
+		return false
+	}
+	ratio := 0.10
+	if e.nodes < yamlAliasHigh {
+		ratio = 0.99 - 0.89*float64(e.nodes-yamlAliasLow)/float64(yamlAliasHigh-yamlAliasLow)
+	}
+	return float64(e.aliasNodes)/float64(e.nodes) > ratio
+}
+
+func (e *yamlExpander) writeJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	return e.visit(n, func(n *yaml.Node) error {
+		switch n.Kind {
+		case 0:
+			buf.WriteString("null")
+			return nil
+		case yaml.DocumentNode:
+			if len(n.Content) == 0 {
+				buf.WriteString("null")
+				return nil
+			}
+			return e.writeJSON(buf, n.Content[0])
+		case yaml.SequenceNode:
+			buf.WriteByte('[')
+			for i, item := range n.Content {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				if err := e.writeJSON(buf, item); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte(']')
+			return nil
+		case yaml.MappingNode:
+			pairs, err := e.mappingPairs(n)
+			if err != nil {
+				return err
+			}
+			buf.WriteByte('{')
+			for i, p := range pairs {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				key, _ := json.Marshal(p.key)
+				buf.Write(key)
+				buf.WriteByte(':')
+				if err := e.writeJSON(buf, p.value); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte('}')
+			return nil
+		case yaml.ScalarNode:
+			return writeYAMLScalarAsJSON(buf, n)
+		}
+		return fmt.Errorf("yaml: tipo de nó não suportado (linha %d)", n.Line)
+	})
+}
+
+type yamlPair struct {
+	key     string
+	keyNode *yaml.Node
+	value   *yaml.Node
+}
+
+// mappingPairs devolve os pares de um mapping na ordem do arquivo,
+// resolvendo merge keys ("<<") e convertendo chaves escalares para string
+// (ex.: o código de status 200 vira "200").
+func (e *yamlExpander) mappingPairs(n *yaml.Node) ([]yamlPair, error) {
+	pairs := make([]yamlPair, 0, len(n.Content)/2)
+	index := make(map[string]int, len(n.Content)/2)
+	set := func(p yamlPair, override bool) {
+		if i, ok := index[p.key]; ok {
+			if override {
+				pairs[i] = p
+			}
+			return
+		}
+		index[p.key] = len(pairs)
+		pairs = append(pairs, p)
+	}
+	var merges []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.Kind == yaml.AliasNode {
+			k = k.Alias
+		}
+		if k.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("yaml: chave não escalar na linha %d", k.Line)
+		}
+		if k.Tag == "!!merge" {
+			merges = append(merges, v)
+			continue
+		}
+		set(yamlPair{key: k.Value, keyNode: k, value: v}, true)
+	}
+	// chaves explícitas têm precedência sobre as herdadas via merge
+	inherit := func(src *yaml.Node) error {
+		if src.Kind != yaml.MappingNode {
+			return fmt.Errorf("yaml: merge key exige mapping na linha %d", src.Line)
+		}
+		inherited, err := e.mappingPairs(src)
+		if err != nil {
+			return err
+		}
+		for _, p := range inherited {
+			set(p, false)
+		}
+		return nil
+	}
+	for _, m := range merges {
+		err := e.visit(m, func(m *yaml.Node) error {
+			if m.Kind != yaml.SequenceNode {
+				return inherit(m)
+			}
+			for _, src := range m.Content {
+				if err := e.visit(src, inherit); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pairs, nil
+}
+
+func writeYAMLScalarAsJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.ShortTag() {
+	case "!!str", "!!timestamp", "!!binary":
+		out, _ := json.Marshal(n.Value)
+		buf.Write(out)
+		return nil
+	case "!!null":
+		buf.WriteString("null")
+		return nil
+	}
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	if f, ok := v.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+		return fmt.Errorf("yaml: valor %q na linha %d não é representável em JSON", n.Value, n.Line)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(out)
+	return nil
+}
+
+// jsonToYAML converte JSON em YAML preservando a ordem das chaves.
+func jsonToYAML(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	node, err := jsonValueToYAMLNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func jsonValueToYAMLNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ := kt.(string)
+				val, err := jsonValueToYAMLNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, val)
+			}
+			_, err := dec.Token()
+			return n, err
+		case '[':
+			n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			for dec.More() {
+				val, err := jsonValueToYAMLNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.Content = append(n.Content, val)
+			}
+			_, err := dec.Token()
+			return n, err
+		}
+		return nil, fmt.Errorf("json: delimitador inesperado %q", v)
+	case string:
+		n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+		if strings.Contains(v, "\n") {
+			// sem estilo o yaml.v3 também escolheria o literal
+			n.Style = yaml.DoubleQuotedStyle
+			if literalSafe(v) {
+				n.Style = yaml.LiteralStyle
+			}
+		}
+		return n, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	case bool:
+		if v {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"}, nil
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+	return nil, fmt.Errorf("json: token inesperado %v", tok)
+}
+
+// literalSafe informa se s pode ir como bloco literal (|) sem mudar ao ser
+// lido de volta: sem espaço no início, tab no início de linha, espaço no fim
+// de linha ou \r. Nos demais casos vai entre aspas duplas.
+func literalSafe(s string) bool {
+	if strings.HasPrefix(s, " ") || strings.ContainsRune(s, '\r') {
+		return false
+	}
+	for _, line := range strings.Split(s, "\n") {
+		if strings.HasPrefix(line, "\t") || strings.HasSuffix(line, " ") || strings.HasSuffix(line, "\t") {
+			return false
+		}
+	}
+	return true
+}
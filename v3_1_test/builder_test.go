@@ -2,8 +2,12 @@ package oas_test
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -302,3 +306,115 @@ func TestBuilder_NewSetters(t *testing.T) {
 	require.Len(t, op.Servers, 1)
 	require.Equal(t, "http://op.example.com", op.Servers[0].URL)
 }
+
+type builderAddress struct {
+	City string `json:"city"`
+}
+
+type builderUser struct {
+	Name    string          `json:"name"`
+	Home    builderAddress  `json:"home"`
+	Work    *builderAddress `json:"work,omitempty"`
+	Manager *builderUser    `json:"manager,omitempty"`
+}
+
+type builderCategory struct {
+	Name     string            `json:"name"`
+	Children []builderCategory `json:"children"`
+}
+
+type builderMoney struct {
+	cents int64
+}
+
+func (builderMoney) OASSchema() oas.Schema {
+	return oas.Schema{Type: oas.TypeString, Pattern: oas.Ptr(`^-?\d+\.\d{2}$`)}
+}
+
+type builderOrder struct {
+	Total    builderMoney  `json:"total"`
+	Discount *builderMoney `json:"discount,omitempty"`
+}
+
+func TestBuilder_SchemaOf(t *testing.T) {
+	b := oas.NewBuilder().SetTitle("API").SetVersion("1")
+	b.Path("/users").
+		Post("Cria").RequestJSONOf(builderUser{}, true).ResponseJSONOf(201, "ok", builderUser{}).DoneOp().
+		Get("Lista").ResponseJSONOf(200, "ok", []builderUser{}).DoneOp()
+	b.Path("/categories").Get("Árvore").ResponseJSONOf(200, "ok", &builderCategory{})
+	doc := b.Build()
+
+	// cada struct é registrado uma vez; os demais lugares usam $ref
+	require.Equal(t, []string{"builderAddress", "builderCategory", "builderUser"}, keys(doc.Components.Schemas))
+	post := doc.Paths["/users"].PathItem.Post
+	require.Equal(t, "#/components/schemas/builderUser", post.RequestBody.Body.Content["application/json"].Schema.Ref.Ref)
+	require.Equal(t, "#/components/schemas/builderUser", post.Responses["201"].Resp.Content["application/json"].Schema.Ref.Ref)
+	list := doc.Paths["/users"].PathItem.Get.Responses["200"].Resp.Content["application/json"].Schema.Schema
+	require.Equal(t, "#/components/schemas/builderUser", list.Items.Single.Ref.Ref)
+	tree := doc.Paths["/categories"].PathItem.Get.Responses["200"].Resp.Content["application/json"].Schema.Schema
+	require.Equal(t, "#/components/schemas/builderCategory", tree.AnyOf[0].Ref.Ref)
+
+	user := doc.Components.Schemas["builderUser"].Schema
+	require.Equal(t, "#/components/schemas/builderAddress", user.Properties["home"].Ref.Ref)
+	require.Equal(t, "#/components/schemas/builderAddress", user.Properties["work"].Schema.AnyOf[0].Ref.Ref)
+	require.Equal(t, "#/components/schemas/builderUser", user.Properties["manager"].Schema.AnyOf[0].Ref.Ref)
+	category := doc.Components.Schemas["builderCategory"].Schema
+	require.Equal(t, "#/components/schemas/builderCategory", category.Properties["children"].Schema.Items.Single.Ref.Ref)
+
+	// o documento gerado é válido e os $ref resolvem
+	require.NoError(t, doc.Validate())
+	v := oas.NewSchemaValidator(oas.NewResolver(doc))
+	require.NoError(t, v.ValidateJSON(oas.SchemaOrRef{Ref: &oas.Reference{Ref: "#/components/schemas/builderCategory"}},
+		[]byte(`{"name": "a", "children": [{"name": "b", "children": []}]}`)))
+}
+
+func TestBuilder_SchemaOfProvider(t *testing.T) {
+	b := oas.NewBuilder().SetTitle("API").SetVersion("1")
+	b.Path("/orders").Get("Lista").ResponseJSONOf(200, "ok", builderOrder{})
+	doc := b.Build()
+
+	// struct com OASSchema também vira componente, com o schema que ele declara
+	require.Equal(t, []string{"builderMoney", "builderOrder"}, keys(doc.Components.Schemas))
+	order := doc.Components.Schemas["builderOrder"].Schema
+	require.Equal(t, "#/components/schemas/builderMoney", order.Properties["total"].Ref.Ref)
+	require.Equal(t, "#/components/schemas/builderMoney", order.Properties["discount"].Schema.AnyOf[0].Ref.Ref)
+	money := doc.Components.Schemas["builderMoney"].Schema
+	require.Equal(t, "string", *money.Type.One)
+	require.NotNil(t, money.Pattern)
+
+	require.NoError(t, doc.Validate())
+	require.Equal(t, "#/components/schemas/builderMoney", b.SchemaOf(reflect.TypeFor[builderMoney]()).Ref.Ref)
+}
+
+func TestBuilder_SchemaNames(t *testing.T) {
+	// mesmo nome em pacotes diferentes: o segundo é qualificado pelo pacote
+	b := oas.NewBuilder().AddSchema("Encoder", oas.Schema{})
+	require.Equal(t, "#/components/schemas/Decoder", b.SchemaOf(reflect.TypeFor[json.Decoder]()).Ref.Ref)
+	require.Equal(t, "#/components/schemas/xml.Decoder", b.SchemaOf(reflect.TypeFor[xml.Decoder]()).Ref.Ref)
+	require.Equal(t, "#/components/schemas/Decoder", b.SchemaOf(reflect.TypeFor[*json.Decoder]()).Schema.AnyOf[0].Ref.Ref)
+	// nome já declarado com AddSchema também conta
+	require.Equal(t, "#/components/schemas/json.Encoder", b.SchemaOf(reflect.TypeFor[json.Encoder]()).Ref.Ref)
+
+	// tipos locais de mesmo nome no mesmo pacote recebem sufixo
+	first := func() reflect.Type { type local struct{ A int }; return reflect.TypeFor[local]() }()
+	second := func() reflect.Type { type local struct{ B int }; return reflect.TypeFor[local]() }()
+	require.Equal(t, "#/components/schemas/local", b.SchemaOf(first).Ref.Ref)
+	require.Equal(t, "#/components/schemas/local2", b.SchemaOf(second).Ref.Ref)
+
+	// namer configurável; tipos não struct continuam inline
+	b = oas.NewBuilder().WithSchemaNamer(func(t reflect.Type) string {
+		return strings.TrimPrefix(t.Name(), "builder")
+	})
+	require.Equal(t, "#/components/schemas/User", b.SchemaOf(reflect.TypeFor[builderUser]()).Ref.Ref)
+	require.Contains(t, b.Build().Components.Schemas, "Address")
+	require.Equal(t, "string", *b.SchemaOf(reflect.TypeFor[string]()).Schema.Type.One)
+}
+
+func keys[T any](m map[string]T) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,187 @@
+package oas_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+const bundleRoot = `
+openapi: 3.1.0
+info: {title: API, version: 1.0.0}
+paths:
+  /users:
+    $ref: './paths/users.yaml'
+  /orders:
+    get:
+      parameters:
+        - $ref: './params.yaml#/Limit'
+        - $ref: 'openapi.yaml#/components/parameters/Local'
+      responses:
+        '200':
+          $ref: './responses.yaml#/Ok'
+        '201':
+          description: criado
+          content:
+            application/json:
+              schema:
+                oneOf:
+                  - $ref: './a.yaml#/Dup'
+                  - $ref: './b.yaml#/Dup'
+                  - $ref: './a.yaml#/Same'
+                  - $ref: './b.yaml#/Same'
+                  - $ref: './tree.yaml#/Node'
+components:
+  schemas:
+    Pet:
+      $ref: './pet.yaml'
+    User:
+      type: string
+  parameters:
+    Local: {name: local, in: query}
+`
+
+var bundleFiles = oas.MapLoader{
+	"api/paths/users.yaml": []byte(`
+get:
+  responses:
+    '200':
+      description: ok
+      content:
+        application/json:
+          schema: {$ref: '../schemas/user.yaml#/User'}
+`),
+	"api/schemas/user.yaml": []byte(`
+User:
+  type: object
+  properties:
+    address: {$ref: '#/Address'}
+    friends:
+      type: array
+      items: {$ref: '#/User'}
+Address: {type: object}
+`),
+	"api/params.yaml":    []byte(`Limit: {name: limit, in: query, schema: {type: integer}}`),
+	"api/responses.yaml": []byte(`Ok: {description: ok}`),
+	"api/a.yaml":         []byte(`{"Dup": {"type": "string", "minLength": 1}, "Same": {"type": "number"}}`),
+	"api/b.yaml":         []byte(`{"Dup": {"type": "integer"}, "Same": {"type": "number"}}`),
+	"api/tree.yaml":      []byte(`Node: {type: object, properties: {children: {type: array, items: {$ref: '#/Node'}}}}`),
+	"api/pet.yaml":       []byte(`{"type": "object", "properties": {"owner": {"$ref": "schemas/user.yaml#/User"}}}`),
+}
+
+func TestBundle(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(bundleRoot))
+	require.NoError(t, err)
+
+	out, err := oas.Bundle(doc, oas.WithBaseURI("api/openapi.yaml"), oas.WithRefLoader(bundleFiles))
+	require.NoError(t, err)
+
+	// documento original intacto
+	require.Equal(t, "./paths/users.yaml", doc.Paths["/users"].Ref.Ref)
+
+	c := out.Components
+	require.Equal(t, "#/components/pathItems/users", out.Paths["/users"].Ref.Ref)
+	require.Contains(t, c.PathItems, "users")
+
+	// conflito de nome com componente local diferente -> User2
+	usersOp := c.PathItems["users"].PathItem.Get
+	require.Equal(t, "#/components/schemas/User2", usersOp.Responses["200"].Resp.Content["application/json"].Schema.Ref.Ref)
+	user := c.Schemas["User2"].Schema
+	require.Equal(t, "#/components/schemas/Address", user.Properties["address"].Ref.Ref)
+	// recursão aponta para o próprio componente
+	require.Equal(t, "#/components/schemas/User2", user.Properties["friends"].Schema.Items.Single.Ref.Ref)
+	require.Equal(t, "string", *c.Schemas["User"].Schema.Type.One)
+
+	// slot de componente com $ref externo recebe o conteúdo
+	pet := c.Schemas["Pet"].Schema
+	require.NotNil(t, pet)
+	require.Equal(t, "#/components/schemas/User2", pet.Properties["owner"].Ref.Ref)
+
+	op := out.Paths["/orders"].PathItem.Get
+	require.Equal(t, "#/components/parameters/Limit", op.Parameters[0].Ref.Ref)
+	require.Equal(t, "#/components/parameters/Local", op.Parameters[1].Ref.Ref)
+	require.Equal(t, "#/components/responses/Ok", op.Responses["200"].Ref.Ref)
+
+	oneOf := op.Responses["201"].Resp.Content["application/json"].Schema.Schema.OneOf
+	require.Equal(t, "#/components/schemas/Dup", oneOf[0].Ref.Ref)
+	require.Equal(t, "#/components/schemas/Dup2", oneOf[1].Ref.Ref)
+	// definições idênticas são deduplicadas
+	require.Equal(t, "#/components/schemas/Same", oneOf[2].Ref.Ref)
+	require.Equal(t, "#/components/schemas/Same", oneOf[3].Ref.Ref)
+	require.NotContains(t, c.Schemas, "Same2")
+	require.Equal(t, "#/components/schemas/Node", c.Schemas["Node"].Schema.Properties["children"].Schema.Items.Single.Ref.Ref)
+
+	// o resultado resolve sem loader
+	data, err := json.Marshal(out)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), ".yaml")
+	r := oas.NewResolver(out)
+	for name := range c.Schemas {
+		_, err := r.ResolveSchema(oas.SchemaOrRef{Ref: ref("#/components/schemas/" + name)})
+		require.NoError(t, err, name)
+	}
+}
+
+func TestBundle_DedupNestedRefs(t *testing.T) {
+	def := []byte(`
+User: {type: object, properties: {addr: {$ref: '#/Addr'}}}
+Addr: {type: string}
+`)
+	doc := &oas.Document{OpenAPI: "3.1.0", Components: &oas.Components{
+		Schemas: map[string]oas.SchemaOrRef{"Root": {Schema: &oas.Schema{
+			OneOf: []oas.SchemaOrRef{{Ref: ref("a.yaml#/User")}, {Ref: ref("b.yaml#/User")}},
+		}}},
+	}}
+	out, err := oas.Bundle(doc, oas.WithRefLoader(oas.MapLoader{"a.yaml": def, "b.yaml": def}))
+	require.NoError(t, err)
+
+	c := out.Components.Schemas
+	require.Equal(t, []string{"Addr", "Root", "User"}, keys(c))
+	require.Equal(t, "#/components/schemas/Addr", c["User"].Schema.Properties["addr"].Ref.Ref)
+	oneOf := c["Root"].Schema.OneOf
+	require.Equal(t, "#/components/schemas/User", oneOf[0].Ref.Ref)
+	require.Equal(t, "#/components/schemas/User", oneOf[1].Ref.Ref)
+}
+
+func TestBundle_WithoutExternalRefs(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(minimalYAML))
+	require.NoError(t, err)
+	out, err := oas.Bundle(doc)
+	require.NoError(t, err)
+	require.Nil(t, out.Components)
+}
+
+func TestBundle_Errors(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(bundleRoot))
+	require.NoError(t, err)
+
+	// sem loader
+	_, err = oas.Bundle(doc)
+	require.ErrorIs(t, err, oas.ErrExternalRef)
+
+	// alvo ausente
+	_, err = oas.Bundle(doc, oas.WithBaseURI("api/openapi.yaml"), oas.WithRefLoader(oas.MapLoader{}))
+	require.Error(t, err)
+
+	// base inválida
+	_, err = oas.Bundle(doc, oas.WithBaseURI("http://[::1"))
+	require.Error(t, err)
+
+	// $ref com fragmento inválido
+	bad := &oas.Document{OpenAPI: "3.1.0", Paths: oas.Paths{"/x": {Ref: ref("#/a%zz")}}}
+	_, err = oas.Bundle(bad)
+	require.ErrorIs(t, err, oas.ErrInvalidPointer)
+
+	// alvo externo incompatível com o tipo
+	wrong := &oas.Document{OpenAPI: "3.1.0", Paths: oas.Paths{"/x": {Ref: ref("x.json#/a")}}}
+	_, err = oas.Bundle(wrong, oas.WithRefLoader(oas.MapLoader{"x.json": []byte(`{"a": 1}`)}))
+	require.Error(t, err)
+	slot := &oas.Document{OpenAPI: "3.1.0", Components: &oas.Components{
+		Schemas: map[string]oas.SchemaOrRef{"X": {Ref: ref("x.json#/a")}},
+	}}
+	_, err = oas.Bundle(slot, oas.WithRefLoader(oas.MapLoader{"x.json": []byte(`{"a": 1}`)}))
+	require.Error(t, err)
+}
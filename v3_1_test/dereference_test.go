@@ -0,0 +1,162 @@
+package oas_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+const dereferenceYAML = `
+openapi: 3.1.0
+info: {title: API, version: 1.0.0}
+paths:
+  /users:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Alias'}
+        '404':
+          $ref: '#/components/responses/NotFound'
+  /tree:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Node'}
+  /external:
+    $ref: './paths/users.yaml'
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name: {type: string}
+    Alias: {$ref: '#/components/schemas/User'}
+    Owner: {$ref: '#/components/schemas/User', description: dono}
+    Node:
+      type: object
+      properties:
+        children:
+          type: array
+          items: {$ref: '#/components/schemas/Node'}
+  parameters:
+    Limit: {name: limit, in: query}
+  responses:
+    NotFound: {description: não encontrado}
+`
+
+func loadDereferenceDoc(t *testing.T) *oas.Document {
+	t.Helper()
+	doc, err := oas.LoadYAML([]byte(dereferenceYAML))
+	require.NoError(t, err)
+	return doc
+}
+
+var dereferenceLoader = oas.WithRefLoader(oas.MapLoader{
+	"paths/users.yaml": []byte(`
+get:
+  responses:
+    '200':
+      description: externo
+      content:
+        application/json:
+          schema: {$ref: '../openapi.yaml#/components/schemas/User'}
+`),
+})
+
+func TestDereference(t *testing.T) {
+	doc := loadDereferenceDoc(t)
+	out, circular, err := oas.Dereference(doc, oas.DereferenceOptions{
+		ResolverOptions: []oas.ResolverOption{oas.WithBaseURI("openapi.yaml"), dereferenceLoader},
+	})
+	require.NoError(t, err)
+
+	get := out.Paths["/users"].PathItem.Get
+	require.Equal(t, "limit", get.Parameters[0].Param.Name)
+	require.Nil(t, get.Parameters[0].Ref)
+	require.Equal(t, "não encontrado", get.Responses["404"].Resp.Description)
+	item := get.Responses["200"].Resp.Content["application/json"].Schema.Schema.Items.Single
+	require.Nil(t, item.Ref)
+	require.Equal(t, "object", *item.Schema.Type.One)
+
+	// $ref externo também é embutido
+	ext := out.Paths["/external"].PathItem.Get.Responses["200"].Resp
+	require.Equal(t, "externo", ext.Description)
+	require.NotNil(t, ext.Content["application/json"].Schema.Schema)
+
+	// components também são desreferenciados
+	require.NotNil(t, out.Components.Schemas["Alias"].Schema)
+	// $ref com irmãos: o alvo entra em allOf
+	owner := out.Components.Schemas["Owner"]
+	require.Nil(t, owner.Ref)
+	require.Equal(t, "dono", *owner.Schema.Description)
+	require.Equal(t, "object", *owner.Schema.AllOf[0].Schema.Type.One)
+
+	// único $ref restante é o circular, mantido
+	tree := out.Paths["/tree"].PathItem.Get.Responses["200"].Resp.Content["application/json"].Schema.Schema
+	require.Equal(t, "#/components/schemas/Node", tree.Properties["children"].Schema.Items.Single.Ref.Ref)
+	node := out.Components.Schemas["Node"].Schema
+	require.Equal(t, "#/components/schemas/Node", node.Properties["children"].Schema.Items.Single.Ref.Ref)
+
+	require.Equal(t, []oas.CircularRef{
+		{Ref: "#/components/schemas/Node", Pointer: "/paths/~1tree/get/responses/200/content/application~1json/schema/properties/children/items"},
+		{Ref: "#/components/schemas/Node", Pointer: "/components/schemas/Node/properties/children/items"},
+	}, circular)
+
+	data, err := json.Marshal(out)
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(string(data), `"$ref"`))
+
+	// documento original intacto
+	require.NotNil(t, doc.Paths["/users"].PathItem.Get.Parameters[0].Ref)
+}
+
+func TestDereference_CircularDepthAndDrop(t *testing.T) {
+	doc := loadDereferenceDoc(t)
+	delete(doc.Paths, "/external")
+
+	out, circular, err := oas.Dereference(doc, oas.DereferenceOptions{CircularDepth: 1, DropCircular: true})
+	require.NoError(t, err)
+	require.Len(t, circular, 2)
+
+	tree := out.Paths["/tree"].PathItem.Get.Responses["200"].Resp.Content["application/json"].Schema.Schema
+	// um nível extra expandido e depois o corte vira schema vazio
+	level1 := tree.Properties["children"].Schema.Items.Single.Schema
+	require.Equal(t, "object", *level1.Type.One)
+	cut := level1.Properties["children"].Schema.Items.Single
+	require.Nil(t, cut.Ref)
+	require.Equal(t, oas.Schema{}, *cut.Schema)
+
+	data, err := json.Marshal(out)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), `"$ref"`)
+}
+
+func TestDereference_Errors(t *testing.T) {
+	// externo sem loader
+	_, _, err := oas.Dereference(loadDereferenceDoc(t), oas.DereferenceOptions{})
+	require.ErrorIs(t, err, oas.ErrExternalRef)
+
+	// ref pendente
+	doc := &oas.Document{OpenAPI: "3.1.0", Paths: oas.Paths{"/x": {Ref: ref("#/components/pathItems/Missing")}}}
+	_, _, err = oas.Dereference(doc, oas.DereferenceOptions{})
+	require.ErrorIs(t, err, oas.ErrRefNotFound)
+
+	// alvo de tipo incompatível
+	doc = &oas.Document{OpenAPI: "3.1.0", Paths: oas.Paths{"/x": {Ref: ref("#/openapi")}}}
+	_, _, err = oas.Dereference(doc, oas.DereferenceOptions{})
+	require.Error(t, err)
+}
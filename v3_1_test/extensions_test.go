@@ -0,0 +1,174 @@
+package oas_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+const extensionsYAML = `openapi: 3.1.0
+info:
+  title: API
+  version: '1'
+  x-logo: {url: https://example.com/logo.png}
+paths:
+  x-owner: {team: core}
+  /users:
+    x-codegen: skip
+    get:
+      x-internal: true
+      parameters:
+        - {name: q, in: query, schema: {type: string, x-nullable: true}, x-order: 1}
+      responses:
+        x-retries: 3
+        '200': {description: ok, x-cache: 60}
+      callbacks:
+        onEvent:
+          x-hook: true
+          '{$request.body#/url}':
+            post: {responses: {'200': {description: ok}}}
+components:
+  securitySchemes:
+    oauth:
+      type: oauth2
+      x-provider: keycloak
+      flows:
+        clientCredentials: {tokenUrl: https://x, scopes: {}, x-audience: api}
+tags:
+  - {name: users, x-displayName: Usuários}
+x-tagGroups:
+  - {name: Core, tags: [users]}
+`
+
+func TestExtensions_RoundTrip(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(extensionsYAML))
+	require.NoError(t, err)
+
+	require.Equal(t, []any{map[string]any{"name": "Core", "tags": []any{"users"}}}, doc.Extensions["x-tagGroups"])
+	require.Equal(t, map[string]any{"url": "https://example.com/logo.png"}, doc.Info.Extensions["x-logo"])
+	users := doc.Paths["/users"].PathItem
+	require.Equal(t, "skip", users.Extensions["x-codegen"])
+	require.Equal(t, true, users.Get.Extensions["x-internal"])
+	param := users.Get.Parameters[0].Param
+	require.Equal(t, float64(1), param.Extensions["x-order"])
+	require.Equal(t, true, param.Schema.Schema.Extensions["x-nullable"])
+	require.Equal(t, float64(60), users.Get.Responses["200"].Resp.Extensions["x-cache"])
+	// Paths, Responses e Callback são mapas: as extensões ficam no dono
+	require.Equal(t, oas.Extensions{"x-owner": map[string]any{"team": "core"}}, doc.PathsExtensions)
+	require.Len(t, doc.Paths, 1)
+	require.Equal(t, oas.Extensions{"x-retries": float64(3)}, users.Get.ResponsesExtensions)
+	require.Len(t, users.Get.Responses, 1)
+	hook := users.Get.Callbacks["onEvent"]
+	require.Equal(t, oas.Extensions{"x-hook": true}, hook.Extensions)
+	require.Len(t, *hook.Callback, 1)
+	require.NoError(t, doc.Validate())
+	oauth := doc.Components.SecuritySchemes["oauth"].Scheme
+	require.Equal(t, "keycloak", oauth.Extensions["x-provider"])
+	require.Equal(t, "api", oauth.Flows.ClientCredentials.Extensions["x-audience"])
+	require.Equal(t, "Usuários", doc.Tags[0].Extensions["x-displayName"])
+	require.Nil(t, doc.Components.Extensions)
+
+	// carregar e salvar não perde nada
+	out, err := doc.YAML()
+	require.NoError(t, err)
+	again, err := oas.LoadYAML(out)
+	require.NoError(t, err)
+	require.Equal(t, doc.Extensions, again.Extensions)
+	require.Equal(t, doc.Tags, again.Tags)
+	require.Equal(t, doc.PathsExtensions, again.PathsExtensions)
+	require.Equal(t, users.Get.ResponsesExtensions, again.Paths["/users"].PathItem.Get.ResponsesExtensions)
+	require.Equal(t, hook.Extensions, again.Paths["/users"].PathItem.Get.Callbacks["onEvent"].Extensions)
+	data, err := json.Marshal(doc.Paths["/users"])
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"x-codegen":"skip"`)
+	require.Contains(t, string(data), `"x-internal":true`)
+
+	// extensões ficam depois dos campos
+	data, err = json.Marshal(oas.Tag{Name: "a", Extensions: oas.Extensions{"x-b": 1, "x-a": 2}})
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"a","x-a":2,"x-b":1}`, string(data))
+	data, err = json.Marshal(oas.Contact{Extensions: oas.Extensions{"x-a": 1}})
+	require.NoError(t, err)
+	require.Equal(t, `{"x-a":1}`, string(data))
+	data, err = json.Marshal(oas.Document{OpenAPI: "3.1.0", PathsExtensions: oas.Extensions{"x-a": 1}})
+	require.NoError(t, err)
+	require.Equal(t, `{"openapi":"3.1.0","info":{"title":"","version":""},"paths":{"x-a":1}}`, string(data))
+	data, err = json.Marshal(oas.Operation{ResponsesExtensions: oas.Extensions{"x-a": 1}})
+	require.NoError(t, err)
+	require.Equal(t, `{"responses":{"x-a":1}}`, string(data))
+	data, err = json.Marshal(oas.CallbackOrRef{Extensions: oas.Extensions{"x-a": 1}})
+	require.NoError(t, err)
+	require.Equal(t, `{"x-a":1}`, string(data))
+
+	// chave sem "x-" não é extensão
+	_, err = json.Marshal(oas.Tag{Name: "a", Extensions: oas.Extensions{"name": "b"}})
+	require.ErrorContains(t, err, `extensão "name" deve começar com x-`)
+}
+
+func TestExtensions_Builder(t *testing.T) {
+	doc := oas.NewBuilder().
+		SetTitle("API").SetVersion("1").
+		SetExtension("x-tagGroups", []string{"core"}).
+		SetInfoExtension("x-logo", "logo.png").
+		Path("/users").SetExtension("x-codegen", "skip").
+		Get("Lista").SetExtension("x-internal", true).DoneOp().
+		DonePath().
+		Build()
+
+	require.Equal(t, oas.Extensions{"x-tagGroups": []string{"core"}}, doc.Extensions)
+	require.Equal(t, "logo.png", doc.Info.Extensions["x-logo"])
+	require.Equal(t, "skip", doc.Paths["/users"].PathItem.Extensions["x-codegen"])
+	require.Equal(t, true, doc.Paths["/users"].PathItem.Get.Extensions["x-internal"])
+
+	var tag oas.Tag
+	tag.Extensions = tag.Extensions.With("x-displayName", "Usuários")
+	require.Equal(t, "Usuários", tag.Extensions["x-displayName"])
+}
+
+func TestExtensions_Strict(t *testing.T) {
+	data := []byte(`openapi: 3.1.0
+info: {title: API, version: '1', x-logo: ok}
+paths:
+  /users:
+    get:
+      opertionId: list
+      parameters:
+        - {name: q, in: query, requried: true, schema: {type: string}}
+      responses:
+        '200': {description: ok, x-cache: 60}
+components:
+  schemas:
+    User:
+      $ref: '#/components/schemas/Base'
+      descripton: typo
+    Base: {type: object, properties: {id: {type: string, x-order: 1, minLenght: 1}}}
+`)
+	// leniente (padrão): campos desconhecidos são ignorados
+	_, err := oas.LoadYAML(data)
+	require.NoError(t, err)
+
+	_, err = oas.Load(context.Background(), oas.FromBytes(data, "api.yaml"), oas.WithStrict(), oas.WithSourceMap())
+	var list oas.ValidationErrors
+	require.ErrorAs(t, err, &list)
+	ptrs := make([]string, len(list))
+	for i, e := range list {
+		ptrs[i] = e.Pointer
+	}
+	require.Equal(t, []string{
+		"/components/schemas/Base/properties/id/minLenght",
+		"/components/schemas/User/descripton",
+		"/paths/~1users/get/opertionId",
+		"/paths/~1users/get/parameters/0/requried",
+	}, ptrs)
+	require.True(t, errors.Is(err, &oas.ValidationError{Code: oas.CodeUnknownField}))
+	require.Contains(t, err.Error(), "api.yaml:6:7: /paths/~1users/get/opertionId: campo desconhecido")
+
+	// documento sem erros de digitação passa
+	_, err = oas.LoadYAML([]byte(extensionsYAML), oas.WithStrict())
+	require.NoError(t, err)
+}
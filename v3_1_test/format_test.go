@@ -0,0 +1,102 @@
+package oas_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+func formatSchema(format string) oas.SchemaOrRef {
+	return oas.SchemaOrRef{Schema: &oas.Schema{Format: &format}}
+}
+
+func TestFormats_BuiltIn(t *testing.T) {
+	v := oas.NewSchemaValidator(nil, oas.WithFormatAssertion(true))
+	cases := []struct {
+		format  string
+		valid   []any
+		invalid []any
+	}{
+		{"date-time", []any{"2024-02-29T10:00:00Z", "2024-02-29T10:00:00.123-03:00"}, []any{"2024-02-30T10:00:00Z", "2024-02-29 10:00"}},
+		{"date", []any{"2024-02-29"}, []any{"2023-02-29", "29/02/2024"}},
+		{"time", []any{"10:00:00Z", "23:59:59.5+01:00"}, []any{"25:00:00Z", "10:00"}},
+		{"duration", []any{"P1D", "PT1H30M", "P2W", "P1Y2M3DT4H5M6S"}, []any{"P", "PT", "1D", "P1H"}},
+		{"email", []any{"ana@example.com"}, []any{"Ana <ana@example.com>", "ana", "joão@example.com"}},
+		{"idn-email", []any{"joão@exemplo.com"}, []any{"joão"}},
+		{"hostname", []any{"example.com", "a-b.c", "localhost"}, []any{"-a.com", "a..b", strings.Repeat("a", 64) + ".com"}},
+		{"ipv4", []any{"192.168.0.1"}, []any{"256.0.0.1", "::1", "01.1.1.1"}},
+		{"ipv6", []any{"::1", "2001:db8::1"}, []any{"192.168.0.1", "fe80::1%eth0", "::g"}},
+		{"uri", []any{"https://example.com/a?b#c", "urn:isbn:123"}, []any{"/relativo", "http://[::1"}},
+		{"uri-reference", []any{"/relativo", "#frag"}, []any{"http://[::1"}},
+		{"uuid", []any{"123e4567-e89b-12d3-a456-426614174000"}, []any{"123e4567e89b12d3a456426614174000"}},
+		{"regex", []any{"^a+$"}, []any{"("}},
+		{"int32", []any{2147483647, -2147483648}, []any{2147483648, 1.5}},
+		{"int64", []any{int64(9223372036854775807)}, []any{uint64(9223372036854775808)}},
+		{"float", []any{3.4e38}, []any{3.5e38}},
+		{"double", []any{1.7e308}, []any{}},
+		{"byte", []any{"aGVsbG8="}, []any{"aGVsbG8", "***"}},
+		{"binary", []any{"\x00\x01"}, nil},
+		{"password", []any{"segredo"}, nil},
+	}
+	for _, c := range cases {
+		for _, value := range c.valid {
+			require.NoError(t, v.Validate(formatSchema(c.format), value), "%s %v", c.format, value)
+		}
+		for _, value := range c.invalid {
+			var errs oas.SchemaErrors
+			require.ErrorAs(t, v.Validate(formatSchema(c.format), value), &errs, "%s %v", c.format, value)
+			require.Equal(t, "format", errs[0].Keyword)
+		}
+	}
+
+	// formato de string não se aplica a números (e vice-versa)
+	require.NoError(t, v.Validate(formatSchema("email"), 1))
+	require.NoError(t, v.Validate(formatSchema("int32"), "x"))
+	// formato desconhecido é ignorado
+	require.NoError(t, v.Validate(formatSchema("desconhecido"), "x"))
+}
+
+func TestFormats_AnnotationByDefault(t *testing.T) {
+	require.NoError(t, oas.NewSchemaValidator(nil).Validate(formatSchema("email"), "não é e-mail"))
+	err := oas.NewSchemaValidator(nil, oas.WithFormatAssertion(true)).Validate(formatSchema("email"), "não é e-mail")
+	require.ErrorContains(t, err, `formato "email" inválido`)
+}
+
+func TestFormats_Custom(t *testing.T) {
+	cpf := oas.StringFormat(func(s string) error {
+		if len(s) != 11 {
+			return errors.New("CPF deve ter 11 dígitos")
+		}
+		return nil
+	})
+	oas.RegisterFormat("cpf", cpf)
+	defer oas.RegisterFormat("cpf", nil)
+
+	v := oas.NewSchemaValidator(nil, oas.WithFormatAssertion(true))
+	require.NoError(t, v.Validate(formatSchema("cpf"), "12345678901"))
+	require.ErrorContains(t, v.Validate(formatSchema("cpf"), "123"), "CPF deve ter 11 dígitos")
+
+	// registro local tem precedência; nil desativa
+	iban := oas.FormatCheckerFunc(func(value any) error { return errors.New("nunca") })
+	local := oas.NewSchemaValidator(nil, oas.WithFormatAssertion(true),
+		oas.WithFormat("iban", iban), oas.WithFormat("cpf", nil), oas.WithFormat("email", nil))
+	require.ErrorContains(t, local.Validate(formatSchema("iban"), "x"), "nunca")
+	require.NoError(t, local.Validate(formatSchema("cpf"), "123"))
+	require.NoError(t, local.Validate(formatSchema("email"), "x"))
+	// outro validador não enxerga o registro local
+	require.NoError(t, v.Validate(formatSchema("iban"), "x"))
+
+	// sobrescrevendo um embutido globalmente
+	oas.RegisterFormat("password", oas.StringFormat(func(s string) error {
+		if len(s) < 8 {
+			return errors.New("curta")
+		}
+		return nil
+	}))
+	defer oas.RegisterFormat("password", oas.FormatCheckerFunc(func(any) error { return nil }))
+	require.Error(t, v.Validate(formatSchema("password"), "123"))
+}
@@ -0,0 +1,242 @@
+package oas_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+const jsonSchemaYAML = `
+openapi: 3.1.0
+info: {title: API, version: '1'}
+components:
+  schemas:
+    User:
+      type: object
+      required: [name, age]
+      properties:
+        name: {type: string, minLength: 2, maxLength: 5, pattern: '^[a-z]+$'}
+        age: {type: integer, minimum: 0, exclusiveMaximum: 150}
+        price: {type: number, multipleOf: 0.01}
+        role: {enum: [admin, user]}
+        kind: {const: person}
+        tags:
+          type: array
+          items: {type: string}
+          uniqueItems: true
+          maxItems: 3
+        friends:
+          type: array
+          items: {$ref: '#/components/schemas/User'}
+      patternProperties:
+        '^x-': {type: string}
+      additionalProperties: false
+    Node:
+      type: object
+      properties:
+        children: {type: array, items: {$ref: '#/components/schemas/Node'}}
+        value: {type: [integer, 'null']}
+`
+
+func newSchemaValidator(t *testing.T) *oas.SchemaValidator {
+	t.Helper()
+	doc, err := oas.LoadYAML([]byte(jsonSchemaYAML))
+	require.NoError(t, err)
+	return oas.NewSchemaValidator(oas.NewResolver(doc))
+}
+
+func schemaFailures(t *testing.T, err error) []string {
+	t.Helper()
+	var errs oas.SchemaErrors
+	require.ErrorAs(t, err, &errs)
+	out := make([]string, len(errs))
+	for i, e := range errs {
+		out[i] = e.InstancePointer + " " + e.SchemaPointer
+	}
+	return out
+}
+
+func TestSchemaValidator(t *testing.T) {
+	v := newSchemaValidator(t)
+	user := oas.SchemaOrRef{Ref: ref("#/components/schemas/User")}
+
+	// válido
+	require.NoError(t, v.ValidateJSON(user, []byte(`{
+		"name": "ana", "age": 30, "price": 19.99, "role": "admin", "kind": "person",
+		"tags": ["a", "b"], "x-note": "ok", "friends": [{"name": "bia", "age": 1.0}]
+	}`)))
+
+	// todas as falhas, com ponteiros da instância e do schema
+	err := v.ValidateJSON(user, []byte(`{
+		"name": "Ana Maria", "price": 0.015, "role": "root", "kind": "bot",
+		"tags": ["a", "a", "b", "c"], "x-note": 1, "extra": true,
+		"friends": [{"name": "b", "age": -1}, {"name": "ok", "age": 150.5}]
+	}`))
+	require.Equal(t, []string{
+		" /$ref/required",
+		"/extra /$ref/additionalProperties",
+		"/friends/0/age /$ref/properties/friends/items/$ref/properties/age/minimum",
+		"/friends/0/name /$ref/properties/friends/items/$ref/properties/name/minLength",
+		"/friends/1/age /$ref/properties/friends/items/$ref/properties/age/type",
+		"/friends/1/age /$ref/properties/friends/items/$ref/properties/age/exclusiveMaximum",
+		"/kind /$ref/properties/kind/const",
+		"/name /$ref/properties/name/maxLength",
+		"/name /$ref/properties/name/pattern",
+		"/price /$ref/properties/price/multipleOf",
+		"/role /$ref/properties/role/enum",
+		"/tags /$ref/properties/tags/maxItems",
+		"/tags /$ref/properties/tags/uniqueItems",
+		"/x-note /$ref/patternProperties/^x-/type",
+	}, schemaFailures(t, err))
+	require.Contains(t, err.Error(), `(raiz): propriedade obrigatória "age" ausente (schema /$ref/required)`)
+
+	// recursão
+	node := oas.SchemaOrRef{Ref: ref("#/components/schemas/Node")}
+	require.NoError(t, v.Validate(node, map[string]any{"value": nil, "children": []any{map[string]any{"value": 1}}}))
+	require.Equal(t, []string{
+		"/children/0/children/0/value /$ref/properties/children/items/$ref/properties/children/items/$ref/properties/value/type",
+	}, schemaFailures(t, v.ValidateJSON(node, []byte(`{"children": [{"children": [{"value": "x"}]}]}`))))
+}
+
+func TestSchemaValidator_Combinators(t *testing.T) {
+	v := newSchemaValidator(t)
+	s := func(js string) oas.SchemaOrRef {
+		var sch oas.SchemaOrRef
+		require.NoError(t, sch.UnmarshalJSON([]byte(js)))
+		return sch
+	}
+
+	oneOf := s(`{"oneOf": [{"type": "integer"}, {"type": "number", "minimum": 10}]}`)
+	require.NoError(t, v.Validate(oneOf, 5))
+	require.NoError(t, v.Validate(oneOf, 10.5))
+	require.Equal(t, []string{" /oneOf"}, schemaFailures(t, v.Validate(oneOf, 20)))
+	require.Equal(t, []string{" /oneOf"}, schemaFailures(t, v.Validate(oneOf, "x")))
+
+	anyOf := s(`{"anyOf": [{"type": "string"}, {"type": "boolean"}]}`)
+	require.NoError(t, v.Validate(anyOf, true))
+	require.Equal(t, []string{" /anyOf"}, schemaFailures(t, v.Validate(anyOf, 1)))
+
+	allOf := s(`{"allOf": [{"minLength": 2}, {"maxLength": 3}]}`)
+	require.Equal(t, []string{" /allOf/1/maxLength"}, schemaFailures(t, v.Validate(allOf, "abcd")))
+
+	not := s(`{"not": [{"type": "null"}]}`)
+	require.NoError(t, v.Validate(not, 0))
+	require.Equal(t, []string{" /not/0"}, schemaFailures(t, v.Validate(not, nil)))
+
+	// prefixItems + items, contains com min/max
+	tuple := s(`{"prefixItems": [{"type": "string"}, {"type": "integer"}], "items": {"type": "boolean"},
+		"contains": {"type": "boolean"}, "minContains": 2, "maxContains": 3}`)
+	require.NoError(t, v.ValidateJSON(tuple, []byte(`["a", 1, true, false]`)))
+	require.Equal(t, []string{
+		"/1 /prefixItems/1/type",
+		"/2 /items/type",
+		" /minContains",
+	}, schemaFailures(t, v.ValidateJSON(tuple, []byte(`["a", "b", 3, true]`))))
+	require.Equal(t, []string{" /maxContains"},
+		schemaFailures(t, v.ValidateJSON(tuple, []byte(`["a", 1, true, true, true, true]`))))
+	contains := s(`{"contains": {"const": 1}}`)
+	require.Equal(t, []string{" /contains"}, schemaFailures(t, v.Validate(contains, []int{2, 3})))
+
+	// objeto: additionalProperties com schema, min/maxProperties
+	obj := s(`{"properties": {"a": {}}, "additionalProperties": {"type": "integer"}, "minProperties": 2}`)
+	require.Equal(t, []string{" /minProperties"}, schemaFailures(t, v.Validate(obj, map[string]any{"a": "x"})))
+	require.Equal(t, []string{"/b /additionalProperties/type"},
+		schemaFailures(t, v.Validate(obj, map[string]any{"a": 1, "b": "x"})))
+
+	// igualdade JSON: 1 == 1.0 e objetos sem ordem
+	require.NoError(t, v.ValidateJSON(s(`{"const": {"a": 1, "b": [1.0]}}`), []byte(`{"b": [1], "a": 1.0}`)))
+	require.Equal(t, []string{" /uniqueItems"}, schemaFailures(t, v.ValidateJSON(s(`{"uniqueItems": true}`), []byte(`[{"a": 1}, {"a": 1.0}]`))))
+}
+
+func TestSchemaValidator_2020Keywords(t *testing.T) {
+	v := newSchemaValidator(t)
+	s := func(js string) oas.SchemaOrRef {
+		var sch oas.SchemaOrRef
+		require.NoError(t, sch.UnmarshalJSON([]byte(js)))
+		return sch
+	}
+
+	// if/then/else
+	cond := s(`{"if": {"properties": {"kind": {"const": "pf"}}}, "then": {"required": ["cpf"]}, "else": {"required": ["cnpj"]}}`)
+	require.NoError(t, v.Validate(cond, map[string]any{"kind": "pf", "cpf": "1"}))
+	require.Equal(t, []string{" /then/required"}, schemaFailures(t, v.Validate(cond, map[string]any{"kind": "pf"})))
+	require.Equal(t, []string{" /else/required"}, schemaFailures(t, v.Validate(cond, map[string]any{"kind": "pj"})))
+
+	// dependentRequired, dependentSchemas e propertyNames
+	deps := s(`{"dependentRequired": {"billing": ["zip"]}, "dependentSchemas": {"card": {"required": ["cvv"]}},
+		"propertyNames": {"maxLength": 4}}`)
+	require.NoError(t, v.Validate(deps, map[string]any{"zip": 1}))
+	require.Equal(t, []string{
+		" /dependentRequired",
+		" /dependentSchemas/card/required",
+		"/billing /propertyNames",
+	}, schemaFailures(t, v.Validate(deps, map[string]any{"billing": 1, "card": 1})))
+
+	// unevaluatedProperties enxerga propriedades avaliadas por allOf, $ref e pelo ramo de if
+	closed := s(`{"allOf": [{"properties": {"a": {}}}], "if": {"properties": {"b": {}}},
+		"unevaluatedProperties": false}`)
+	require.NoError(t, v.Validate(closed, map[string]any{"a": 1, "b": 2}))
+	require.Equal(t, []string{"/c /unevaluatedProperties"}, schemaFailures(t, v.Validate(closed, map[string]any{"a": 1, "c": 3})))
+	// ramos de anyOf que falham não contam
+	branches := s(`{"anyOf": [{"properties": {"a": {"type": "string"}}}, {"properties": {"b": {}}}],
+		"unevaluatedProperties": {"type": "integer"}}`)
+	require.NoError(t, v.Validate(branches, map[string]any{"a": "x", "c": 1}))
+	require.Equal(t, []string{"/a /unevaluatedProperties/type"}, schemaFailures(t, v.Validate(branches, map[string]any{"a": true, "b": 2})))
+
+	// unevaluatedItems considera prefixItems e contains
+	items := s(`{"prefixItems": [{"type": "string"}], "contains": {"type": "boolean"}, "unevaluatedItems": false}`)
+	require.NoError(t, v.ValidateJSON(items, []byte(`["a", true, false]`)))
+	require.Equal(t, []string{"/2 /unevaluatedItems"}, schemaFailures(t, v.ValidateJSON(items, []byte(`["a", true, 1]`))))
+}
+
+func TestSchemaValidator_BoolAndRefSiblings(t *testing.T) {
+	v := newSchemaValidator(t)
+	s := func(js string) oas.SchemaOrRef {
+		var sch oas.SchemaOrRef
+		require.NoError(t, sch.UnmarshalJSON([]byte(js)))
+		return sch
+	}
+
+	// schemas booleanos
+	require.NoError(t, v.Validate(s(`true`), map[string]any{"x": 1}))
+	require.Equal(t, []string{" "}, schemaFailures(t, v.Validate(s(`false`), 1)))
+	closed := s(`{"prefixItems": [true], "items": false, "properties": {"never": false}}`)
+	require.NoError(t, v.ValidateJSON(closed, []byte(`[1]`)))
+	require.Equal(t, []string{"/1 /items"}, schemaFailures(t, v.ValidateJSON(closed, []byte(`[1, 2]`))))
+	require.Equal(t, []string{"/never /properties/never"}, schemaFailures(t, v.Validate(closed, map[string]any{"never": 1})))
+
+	// irmãos de $ref valem junto com o alvo, e unevaluatedProperties enxerga o $ref
+	sib := s(`{"$ref": "#/components/schemas/Node", "required": ["value"], "unevaluatedProperties": false}`)
+	require.NoError(t, v.Validate(sib, map[string]any{"value": 1}))
+	require.Equal(t, []string{
+		" /required",
+		"/extra /unevaluatedProperties",
+	}, schemaFailures(t, v.Validate(sib, map[string]any{"extra": 1})))
+	// $ref reprovado não anota nada: "value" também fica não avaliada
+	require.Equal(t, []string{
+		"/value /$ref/properties/value/type",
+		"/value /unevaluatedProperties",
+	}, schemaFailures(t, v.Validate(sib, map[string]any{"value": "x"})))
+}
+
+func TestSchemaValidator_Errors(t *testing.T) {
+	v := newSchemaValidator(t)
+
+	// $ref pendente não é falha da instância
+	err := v.Validate(oas.SchemaOrRef{Ref: ref("#/components/schemas/Missing")}, 1)
+	require.ErrorIs(t, err, oas.ErrRefNotFound)
+
+	// regex inválida
+	pattern := "("
+	err = v.Validate(oas.SchemaOrRef{Schema: &oas.Schema{Pattern: &pattern}}, "x")
+	require.Error(t, err)
+
+	// JSON inválido
+	require.Error(t, v.ValidateJSON(oas.SchemaOrRef{Schema: &oas.Schema{}}, []byte(`{`)))
+
+	// sem Resolver, $ref não resolve
+	err = oas.NewSchemaValidator(nil).Validate(oas.SchemaOrRef{Ref: ref("#/x")}, 1)
+	require.ErrorIs(t, err, oas.ErrExternalRef)
+}
@@ -0,0 +1,195 @@
+package oas_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+const minimalJSON = `{"openapi":"3.1.0","info":{"title":"API","version":"1.0.0"},"paths":{}}`
+
+const minimalYAML = `
+openapi: 3.1.1
+info:
+  title: API
+  version: 1.0.0
+`
+
+func TestLoad_Sources(t *testing.T) {
+	ctx := context.Background()
+
+	// arquivo
+	{
+		file := filepath.Join(t.TempDir(), "openapi.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(minimalYAML), 0o600))
+		doc, err := oas.Load(ctx, oas.FromFile(file))
+		require.NoError(t, err)
+		require.Equal(t, "3.1.1", doc.OpenAPI)
+	}
+	// bytes
+	{
+		doc, err := oas.Load(ctx, oas.FromBytes([]byte(minimalJSON)))
+		require.NoError(t, err)
+		require.Equal(t, "API", doc.Info.Title)
+	}
+	// io.Reader
+	{
+		doc, err := oas.Load(ctx, oas.FromReader(strings.NewReader(minimalYAML), "spec"))
+		require.NoError(t, err)
+		require.Equal(t, "1.0.0", doc.Info.Version)
+	}
+	// fs.FS
+	{
+		fsys := fstest.MapFS{"api/openapi.json": {Data: []byte(minimalJSON)}}
+		doc, err := oas.Load(ctx, oas.FromFS(fsys, "api/openapi.json"))
+		require.NoError(t, err)
+		require.Equal(t, "3.1.0", doc.OpenAPI)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	require.Equal(t, oas.FormatJSON, oas.DetectFormat("a.JSON", []byte("openapi: 3.1.0")))
+	require.Equal(t, oas.FormatYAML, oas.DetectFormat("a.yml", []byte(minimalJSON)))
+	require.Equal(t, oas.FormatYAML, oas.DetectFormat("a.yaml", nil))
+	require.Equal(t, oas.FormatJSON, oas.DetectFormat("", []byte("\xef\xbb\xbf \n "+minimalJSON)))
+	require.Equal(t, oas.FormatYAML, oas.DetectFormat("", []byte(minimalYAML)))
+}
+
+func TestLoad_BOM(t *testing.T) {
+	ctx := context.Background()
+	for _, src := range []string{minimalJSON, minimalYAML} {
+		data := []byte("\xef\xbb\xbf" + src)
+		doc, err := oas.Load(ctx, oas.FromBytes(data), oas.WithSourceMap())
+		require.NoError(t, err)
+		require.Equal(t, "API", doc.Info.Title)
+
+		var again oas.Document
+		require.NoError(t, oas.Unmarshal(data, &again))
+		require.Equal(t, doc.Info, again.Info)
+	}
+
+	// também em $ref externos
+	doc := &oas.Document{OpenAPI: "3.1.0", Components: &oas.Components{
+		Schemas: map[string]oas.SchemaOrRef{"A": {Ref: ref("a.json#/A")}},
+	}}
+	r := oas.NewResolver(doc, oas.WithRefLoader(oas.MapLoader{"a.json": []byte("\xef\xbb\xbf{\"A\": {\"type\": \"string\"}}")}))
+	s, err := r.ResolveSchema(doc.Components.Schemas["A"])
+	require.NoError(t, err)
+	require.Equal(t, "string", *s.Type.One)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	// versão ausente
+	{
+		_, err := oas.Load(ctx, oas.FromBytes([]byte(`{"info":{"title":"x","version":"1"}}`), "a.json"))
+		require.ErrorIs(t, err, oas.ErrMissingVersion)
+		var le *oas.LoadError
+		require.ErrorAs(t, err, &le)
+		require.Equal(t, "a.json", le.Source)
+		require.Equal(t, oas.FormatJSON, le.Format)
+		require.Contains(t, err.Error(), "a.json (json)")
+	}
+	// versão não suportada
+	{
+		_, err := oas.Load(ctx, oas.FromBytes([]byte("openapi: 3.0.3\n")))
+		require.ErrorIs(t, err, oas.ErrUnsupportedVersion)
+		var ve *oas.VersionError
+		require.ErrorAs(t, err, &ve)
+		require.Equal(t, "3.0.3", ve.Version)
+		require.Contains(t, err.Error(), "<documento>")
+	}
+	// versão não string (YAML numérico)
+	{
+		_, err := oas.Load(ctx, oas.FromBytes([]byte("openapi: 3.1\n")))
+		require.ErrorIs(t, err, oas.ErrUnsupportedVersion)
+	}
+	// json inválido
+	{
+		_, err := oas.Load(ctx, oas.FromBytes([]byte(`{"openapi":`)))
+		require.Error(t, err)
+	}
+	// yaml inválido
+	{
+		_, err := oas.Load(ctx, oas.FromBytes([]byte("openapi: [")))
+		require.Error(t, err)
+	}
+	// estrutura inválida
+	{
+		_, err := oas.Load(ctx, oas.FromBytes([]byte(`{"openapi":"3.1.0","info":"x"}`)))
+		require.Error(t, err)
+	}
+	// arquivo inexistente
+	{
+		_, err := oas.Load(ctx, oas.FromFile(filepath.Join(t.TempDir(), "nope.yaml")))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+		var le *oas.LoadError
+		require.ErrorAs(t, err, &le)
+		require.Empty(t, le.Format)
+		require.NotContains(t, err.Error(), "(")
+	}
+	// arquivo inexistente no fs.FS
+	{
+		_, err := oas.Load(ctx, oas.FromFS(fstest.MapFS{}, "openapi.yaml"))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	}
+	// leitor com erro
+	{
+		_, err := oas.Load(ctx, oas.FromReader(errReader{}))
+		require.Error(t, err)
+	}
+}
+
+func TestLoad_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sources := []oas.Source{
+		oas.FromFile("openapi.yaml"),
+		oas.FromBytes([]byte(minimalJSON)),
+		oas.FromReader(strings.NewReader(minimalJSON)),
+		oas.FromFS(fstest.MapFS{}, "openapi.yaml"),
+	}
+	for _, src := range sources {
+		_, err := oas.Load(ctx, src)
+		require.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var doc oas.Document
+	require.NoError(t, oas.Unmarshal([]byte(minimalYAML), &doc))
+	require.Equal(t, "API", doc.Info.Title)
+
+	var op oas.Operation
+	data := []byte(`{"operationId": "list", "opertionId": "x", "responses": {"200": {"description": "ok", "x-cache": 1}}}`)
+	require.NoError(t, oas.Unmarshal(data, &op))
+	require.Equal(t, "list", *op.OperationID)
+
+	err := oas.Unmarshal(data, &op, oas.WithStrict())
+	require.True(t, errors.Is(err, &oas.ValidationError{Code: oas.CodeUnknownField, Pointer: "/opertionId"}))
+
+	// Items e AdditionalProperties com $ref também são conferidos
+	var schema oas.Schema
+	data = []byte("type: array\nitems: {$ref: '#/$defs/a', descripton: x}\nadditionalProperties: {$ref: '#/$defs/b'}\n")
+	err = oas.Unmarshal(data, &schema, oas.WithStrict(), oas.WithSourceMap())
+	require.EqualError(t, err, "2:28: /items/descripton: campo desconhecido")
+
+	err = oas.Unmarshal([]byte(`{"type": 1}`), &schema)
+	require.Error(t, err)
+	require.Error(t, oas.Unmarshal([]byte(`{}`), schema))
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("falha de leitura") }
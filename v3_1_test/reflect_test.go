@@ -0,0 +1,598 @@
+package oas_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+type reflectUUID [16]byte
+
+func (u reflectUUID) MarshalText() ([]byte, error) {
+	return []byte("00000000-0000-0000-0000-000000000000"), nil
+}
+
+type reflectBase struct {
+	ID        reflectUUID `json:"id"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Name      string      `json:"base_name"` // sombreado por reflectUser.Name
+}
+
+type reflectAudit struct {
+	By string `json:"by"`
+}
+
+type reflectUser struct {
+	reflectBase
+	*reflectAudit
+	Name     string          `json:"name"`
+	Email    *string         `json:"email"`
+	Age      int32           `json:"age,omitempty"`
+	Balance  int64           `json:"balance,string"`
+	Score    *float64        `json:"score,string,omitempty"`
+	Tags     []string        `json:"tags"`
+	Avatar   []byte          `json:"avatar,omitzero"`
+	Labels   map[string]int  `json:"labels,omitempty"`
+	Meta     json.RawMessage `json:"meta,omitempty"`
+	Any      any             `json:"any,omitempty"`
+	Pair     [2]float32      `json:"pair"`
+	Internal string          `json:"-"`
+	Dash     bool            `json:"-,"`
+	Untagged uint8
+	secret   string
+	Nested   struct{ X int }   `json:"nested"`
+	Children map[string]string `json:"children,omitempty"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	data, err := json.Marshal(oas.SchemaFor[reflectUser]())
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"createdAt": {"type": "string", "format": "date-time"},
+			"base_name": {"type": "string"},
+			"by": {"type": "string"},
+			"name": {"type": "string"},
+			"email": {"type": ["string", "null"]},
+			"age": {"type": "integer", "format": "int32"},
+			"balance": {"type": "string"},
+			"score": {"type": ["string", "null"]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"avatar": {"type": "string", "contentEncoding": "base64"},
+			"labels": {"type": "object", "additionalProperties": {"type": "integer", "format": "int64"}},
+			"meta": {},
+			"any": {},
+			"pair": {"type": "array", "items": {"type": "number", "format": "float"}, "minItems": 2, "maxItems": 2},
+			"-": {"type": "boolean"},
+			"Untagged": {"type": "integer", "minimum": 0},
+			"nested": {"type": "object", "properties": {"X": {"type": "integer", "format": "int64"}}, "required": ["X"]},
+			"children": {"type": "object", "additionalProperties": {"type": "string"}}
+		},
+		"required": ["id", "createdAt", "base_name", "name", "email", "balance", "tags", "pair", "-", "Untagged", "nested"]
+	}`, string(data))
+
+	require.Equal(t, []string{"integer", "null"}, oas.SchemaFor[*int]().Type.Many)
+	require.Equal(t, "date-time", *oas.SchemaOf(reflect.TypeOf(time.Time{})).Format)
+}
+
+type reflectA struct {
+	Name string
+}
+
+type reflectB struct {
+	Name string
+}
+
+type reflectConflict struct {
+	reflectA
+	reflectB
+	Extra string `json:"extra"`
+}
+
+func TestSchemaFor_EmbeddedConflict(t *testing.T) {
+	// campos de mesmo nome e profundidade se anulam, como em encoding/json
+	s := oas.SchemaFor[reflectConflict]()
+	require.Len(t, s.Properties, 1)
+	require.Contains(t, s.Properties, "extra")
+}
+
+type reflectTree struct {
+	Value    int            `json:"value"`
+	Children []*reflectTree `json:"children,omitempty"`
+}
+
+type reflectForest struct {
+	Trees []reflectNode `json:"trees"`
+}
+
+type reflectNode struct {
+	Parent *reflectNode `json:"parent"`
+}
+
+// reflectID prefixa o $id dos schemas recursivos gerados neste pacote.
+const reflectID = "urn:go-oas:github.com/leandroluk/go-oas/v3_1_test_test."
+
+func TestSchemaFor_Recursive(t *testing.T) {
+	data, err := json.Marshal(oas.SchemaFor[reflectTree]())
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"$id": "`+reflectID+`reflectTree",
+		"type": "object",
+		"properties": {
+			"value": {"type": "integer", "format": "int64"},
+			"children": {"type": "array", "items": {"anyOf": [{"$ref": "`+reflectID+`reflectTree"}, {"type": "null"}]}}
+		},
+		"required": ["value"]
+	}`, string(data))
+
+	data, err = json.Marshal(oas.SchemaFor[reflectForest]())
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"$id": "`+reflectID+`reflectForest",
+		"$defs": {
+			"reflectNode": {
+				"type": "object",
+				"properties": {"parent": {"anyOf": [{"$ref": "`+reflectID+`reflectForest#/$defs/reflectNode"}, {"type": "null"}]}},
+				"required": ["parent"]
+			}
+		},
+		"type": "object",
+		"properties": {"trees": {"type": "array", "items": {"$ref": "`+reflectID+`reflectForest#/$defs/reflectNode"}}},
+		"required": ["trees"]
+	}`, string(data))
+
+	// o $id mantém os $ref válidos com o schema embutido num Document
+	doc := &oas.Document{
+		OpenAPI: "3.1.0",
+		Info:    oas.Info{Title: "API", Version: "1"},
+		Components: &oas.Components{Schemas: map[string]oas.SchemaOrRef{
+			"Tree":   {Schema: oas.SchemaFor[reflectTree]()},
+			"Forest": {Schema: oas.SchemaFor[reflectForest]()},
+		}},
+	}
+	require.NoError(t, doc.Validate())
+	v := oas.NewSchemaValidator(oas.NewResolver(doc))
+	tree := oas.SchemaOrRef{Ref: &oas.Reference{Ref: "#/components/schemas/Tree"}}
+	require.NoError(t, v.ValidateJSON(tree, []byte(`{"value": 1, "children": [{"value": 2, "children": [null]}]}`)))
+	require.Error(t, v.ValidateJSON(tree, []byte(`{"value": 1, "children": [{"children": []}]}`)))
+	forest := oas.SchemaOrRef{Ref: &oas.Reference{Ref: "#/components/schemas/Forest"}}
+	require.NoError(t, v.ValidateJSON(forest, []byte(`{"trees": [{"parent": {"parent": null}}]}`)))
+	require.Error(t, v.ValidateJSON(forest, []byte(`{"trees": [{"parent": {}}]}`)))
+	bundled, err := oas.Bundle(doc)
+	require.NoError(t, err)
+	require.Equal(t, doc.Components.Schemas, bundled.Components.Schemas)
+	_, circular, err := oas.Dereference(doc, oas.DereferenceOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, circular)
+}
+
+type reflectTagged struct {
+	Name    string   `json:"name" oas:"title=Nome,description=Nome completo\\, com sobrenome,minLength=1,maxLength=64,example=Ana"`
+	Email   string   `json:"email" jsonschema:"format=email,pattern=^.+@.+$,readOnly,required"`
+	Age     *int     `json:"age" oas:"minimum=0,maximum=150,default=18,example=30"`
+	Status  string   `json:"status" oas:"enum=active,enum=disabled,default=active"`
+	Rate    float64  `json:"rate" oas:"enum=0.5,enum=1"`
+	Admin   bool     `json:"admin" oas:"deprecated,writeOnly=false,default=true"`
+	Roles   []string `json:"roles" oas:"example=[\"admin\"\\,\"user\"]"`
+	Comment string   `json:"comment" oas:"example=[1\\,2]"`
+}
+
+func TestSchemaFor_Tags(t *testing.T) {
+	s := oas.SchemaFor[reflectTagged]()
+	name := s.Properties["name"].Schema
+	require.Equal(t, "Nome", *name.Title)
+	require.Equal(t, "Nome completo, com sobrenome", *name.Description)
+	require.Equal(t, 1, *name.MinLength)
+	require.Equal(t, 64, *name.MaxLength)
+	require.Equal(t, "Ana", name.Example)
+
+	email := s.Properties["email"].Schema
+	require.Equal(t, "email", *email.Format)
+	require.Equal(t, "^.+@.+$", *email.Pattern)
+	require.True(t, *email.ReadOnly)
+
+	age := s.Properties["age"].Schema
+	require.Equal(t, 0.0, *age.Minimum)
+	require.Equal(t, 150.0, *age.Maximum)
+	require.Equal(t, int64(18), age.Default)
+	require.Equal(t, int64(30), age.Example)
+
+	require.Equal(t, oas.Enum{"active", "disabled"}, s.Properties["status"].Schema.Enum)
+	require.Equal(t, "active", s.Properties["status"].Schema.Default)
+	require.Equal(t, oas.Enum{0.5, 1.0}, s.Properties["rate"].Schema.Enum)
+
+	admin := s.Properties["admin"].Schema
+	require.True(t, *admin.Deprecated)
+	require.False(t, *admin.WriteOnly)
+	require.Equal(t, true, admin.Default)
+
+	require.Equal(t, []any{"admin", "user"}, s.Properties["roles"].Schema.Example)
+	require.Equal(t, "[1,2]", s.Properties["comment"].Schema.Example)
+
+	// em $ref as tags viram keywords irmãos
+	type node struct {
+		Next *node `json:"next" oas:"description=Próximo"`
+		Self *node `json:"self"`
+	}
+	n := oas.SchemaFor[node]()
+	require.Equal(t, "Próximo", *n.Properties["next"].Schema.Description)
+	require.NotNil(t, n.Properties["next"].Schema.AnyOf[0].Ref)
+
+	// tag oas inválida: SchemaFor entra em pânico, ReflectSchemaFor devolve o erro
+	type badMin struct {
+		N int `oas:"minimum=x"`
+	}
+	const badMinErr = `oas: campo badMin.N: tag oas "minimum=x": strconv.ParseFloat: parsing "x": invalid syntax`
+	require.PanicsWithError(t, badMinErr, func() {
+		oas.SchemaFor[badMin]()
+	})
+	_, err := oas.ReflectSchemaFor[badMin]()
+	require.EqualError(t, err, badMinErr)
+	type badKey struct {
+		N int `oas:"minimun=1"`
+		M int `oas:"pattern=(a"`
+	}
+	require.Panics(t, func() { oas.SchemaFor[badKey]() })
+	_, err = oas.ReflectSchema(reflect.TypeFor[badKey]())
+	require.ErrorContains(t, err, "badKey.N")
+	require.ErrorContains(t, err, "badKey.M")
+
+	// no Builder, o erro sai em Err, JSON e YAML
+	b := oas.NewBuilder()
+	b.Path("/x").Get("x").ResponseJSONOf(200, "ok", badMin{})
+	require.EqualError(t, b.Err(), badMinErr)
+	_, err = b.JSON()
+	require.EqualError(t, err, badMinErr)
+	_, err = b.YAML()
+	require.EqualError(t, err, badMinErr)
+	require.NoError(t, oas.NewBuilder().Err())
+
+	// pattern ECMA-262 que o RE2 não suporta é aceito
+	type lookahead struct {
+		P string `json:"p" oas:"pattern=^(?=.*\\d).+$"`
+	}
+	la, err := oas.ReflectSchemaFor[lookahead]()
+	require.NoError(t, err)
+	require.Equal(t, `^(?=.*\d).+$`, *la.Properties["p"].Schema.Pattern)
+}
+
+type reflectValidated struct {
+	Name     string            `json:"name,omitempty" validate:"required,min=1,max=64"`
+	Email    *string           `json:"email" validate:"required,email"`
+	Code     string            `json:"code" validate:"len=6,alphanum"`
+	Color    string            `json:"color" validate:"oneof=red green 'light blue'"`
+	Level    int               `json:"level" validate:"oneof=1 2 3,gte=1,lt=4"`
+	Score    float64           `json:"score" validate:"gt=0,lte=10"`
+	Tags     []string          `json:"tags" validate:"min=1,max=5,unique,dive,min=2,max=10"`
+	IDs      []string          `json:"ids" validate:"dive,uuid"`
+	Labels   map[string]string `json:"labels" validate:"max=3,dive,keys,alpha,endkeys,url"`
+	Contact  string            `json:"contact" validate:"email|url,omitempty"`
+	Parent   *reflectAudit     `json:"parent" validate:"required"`
+	Optional *int              `json:"optional,omitempty" validate:"omitempty,min=1"`
+}
+
+func TestSchemaFor_Validate(t *testing.T) {
+	s := oas.SchemaFor[reflectValidated]()
+	require.ElementsMatch(t, oas.Required{"name", "email", "code", "color", "level", "score", "tags", "ids", "labels", "contact", "parent"}, s.Required)
+
+	name := s.Properties["name"].Schema
+	require.Equal(t, 1, *name.MinLength)
+	require.Equal(t, 64, *name.MaxLength)
+
+	email := s.Properties["email"].Schema
+	require.Equal(t, "string", *email.Type.One) // required: não aceita null
+	require.Equal(t, "email", *email.Format)
+
+	code := s.Properties["code"].Schema
+	require.Equal(t, 6, *code.MinLength)
+	require.Equal(t, 6, *code.MaxLength)
+	require.Equal(t, "^[a-zA-Z0-9]+$", *code.Pattern)
+
+	require.Equal(t, oas.Enum{"red", "green", "light blue"}, s.Properties["color"].Schema.Enum)
+	level := s.Properties["level"].Schema
+	require.Equal(t, oas.Enum{int64(1), int64(2), int64(3)}, level.Enum)
+	require.Equal(t, 1.0, *level.Minimum)
+	require.Equal(t, 4.0, *level.ExclusiveMaximum)
+	score := s.Properties["score"].Schema
+	require.Equal(t, 0.0, *score.ExclusiveMinimum)
+	require.Equal(t, 10.0, *score.Maximum)
+
+	tags := s.Properties["tags"].Schema
+	require.Equal(t, 1, *tags.MinItems)
+	require.Equal(t, 5, *tags.MaxItems)
+	require.True(t, *tags.UniqueItems)
+	require.Equal(t, 2, *tags.Items.Single.Schema.MinLength)
+	require.Equal(t, 10, *tags.Items.Single.Schema.MaxLength)
+	require.Equal(t, "uuid", *s.Properties["ids"].Schema.Items.Single.Schema.Format)
+
+	labels := s.Properties["labels"].Schema
+	require.Equal(t, 3, *labels.MaxProperties)
+	require.Equal(t, "^[a-zA-Z]+$", *labels.PropertyNames.Schema.Pattern)
+	require.Equal(t, "uri", *labels.AdditionalProperties.Schema.Schema.Format)
+
+	require.Nil(t, s.Properties["contact"].Schema.Format) // alternativas são ignoradas
+	require.Equal(t, "object", *s.Properties["parent"].Schema.Type.One)
+	type node struct {
+		Next *node `json:"next" validate:"required"`
+	}
+	next := oas.SchemaFor[node]().Properties["next"].Schema
+	require.Len(t, next.AnyOf, 1)
+	require.Equal(t, reflectID+"node", next.AnyOf[0].Ref.Ref)
+	require.Equal(t, 1.0, *s.Properties["optional"].Schema.Minimum)
+
+	// o payload que o validator aceita também passa no schema
+	v := oas.NewSchemaValidator(nil, oas.WithFormatAssertion(true))
+	payload := `{"name": "Ana", "email": "ana@example.com", "code": "abc123", "color": "light blue",
+		"level": 2, "score": 9.5, "tags": ["go", "oas"], "ids": [], "labels": {"docs": "https://example.com"},
+		"contact": "x", "parent": null}`
+	err := v.ValidateJSON(oas.SchemaOrRef{Schema: s}, []byte(payload))
+	var failures oas.SchemaErrors
+	require.ErrorAs(t, err, &failures)
+	require.Len(t, failures, 1)
+	require.Equal(t, "/parent", failures[0].InstancePointer)
+
+	// regras sem equivalente na forma JSON do campo são ignoradas
+	type encoded struct {
+		B   []byte `json:"b" validate:"dive,min=1"`
+		Raw []byte `json:"raw" validate:"min=1,max=4,unique"`
+		N   int    `json:"n,string" validate:"min=1"`
+	}
+	es := oas.SchemaFor[encoded]()
+	require.Equal(t, "base64", *es.Properties["b"].Schema.ContentEncoding)
+	raw := es.Properties["raw"].Schema
+	require.Nil(t, raw.MinItems)
+	require.Nil(t, raw.MaxItems)
+	require.Nil(t, raw.MaxLength)
+	require.Nil(t, raw.UniqueItems)
+	require.Nil(t, es.Properties["n"].Schema.Minimum)
+
+	// limites de tamanho negativos são ignorados; em números valem
+	type negative struct {
+		S string         `json:"s" validate:"lt=0,min=-1"`
+		L []int          `json:"l" validate:"lt=0,gt=-1"`
+		M map[string]int `json:"m" validate:"max=-1"`
+		N int            `json:"n" validate:"lt=0"`
+	}
+	ns, err := oas.ReflectSchemaFor[negative]()
+	require.NoError(t, err)
+	require.Nil(t, ns.Properties["s"].Schema.MaxLength)
+	require.Nil(t, ns.Properties["s"].Schema.MinLength)
+	require.Nil(t, ns.Properties["l"].Schema.MaxItems)
+	require.Equal(t, 0, *ns.Properties["l"].Schema.MinItems)
+	require.Nil(t, ns.Properties["m"].Schema.MaxProperties)
+	require.Equal(t, float64(0), *ns.Properties["n"].Schema.ExclusiveMaximum)
+	doc := &oas.Document{OpenAPI: "3.1.0", Info: oas.Info{Title: "API", Version: "1"}, Components: &oas.Components{
+		Schemas: map[string]oas.SchemaOrRef{"negative": {Schema: ns}},
+	}}
+	require.NoError(t, doc.Validate())
+
+	require.Panics(t, func() {
+		type bad struct {
+			N int `validate:"min=x"`
+		}
+		oas.SchemaFor[bad]()
+	})
+}
+
+type reflectQuery struct {
+	Sort  *string `json:"sort" validate:"omitempty,oneof=asc desc"`
+	Kind  *string `json:"kind" oas:"enum=a,enum=b"`
+	Order *string `json:"order" validate:"required,oneof=asc desc"`
+}
+
+func TestSchemaFor_NullableEnum(t *testing.T) {
+	s := oas.SchemaFor[reflectQuery]()
+	require.Equal(t, oas.Enum{"asc", "desc", nil}, s.Properties["sort"].Schema.Enum)
+	require.Equal(t, oas.Enum{"a", "b", nil}, s.Properties["kind"].Schema.Enum)
+	// required: nem type nem enum aceitam null
+	require.Equal(t, oas.Enum{"asc", "desc"}, s.Properties["order"].Schema.Enum)
+
+	v := oas.NewSchemaValidator(nil)
+	schema := oas.SchemaOrRef{Schema: s}
+	require.NoError(t, v.ValidateJSON(schema, []byte(`{"sort": null, "kind": null, "order": "asc"}`)))
+	require.NoError(t, v.ValidateJSON(schema, []byte(`{"sort": "desc", "kind": "b", "order": "desc"}`)))
+	require.Error(t, v.ValidateJSON(schema, []byte(`{"sort": "up", "kind": null, "order": "asc"}`)))
+	require.Error(t, v.ValidateJSON(schema, []byte(`{"sort": null, "kind": null, "order": null}`)))
+}
+
+type reflectPage[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+type reflectResult[T, E any] struct {
+	Data  *T `json:"data,omitempty"`
+	Error *E `json:"error,omitempty"`
+}
+
+type reflectOrder struct {
+	ID string `json:"id"`
+}
+
+type reflectFailure struct {
+	Message string `json:"message"`
+}
+
+func TestSchemaNamer_Generics(t *testing.T) {
+	b := oas.NewBuilder()
+	page := b.SchemaOf(reflect.TypeFor[reflectPage[reflectOrder]]())
+	require.Equal(t, "#/components/schemas/reflectPageReflectOrder", page.Ref.Ref)
+	result := b.SchemaOf(reflect.TypeFor[reflectResult[reflectOrder, reflectFailure]]())
+	require.Equal(t, "#/components/schemas/reflectResultReflectOrderReflectFailure", result.Ref.Ref)
+
+	schemas := b.Build().Components.Schemas
+	items := schemas["reflectPageReflectOrder"].Schema.Properties["items"].Schema
+	require.Equal(t, "#/components/schemas/reflectOrder", items.Items.Single.Ref.Ref)
+	data := schemas["reflectResultReflectOrderReflectFailure"].Schema.Properties["data"].Schema
+	require.Equal(t, "#/components/schemas/reflectOrder", data.AnyOf[0].Ref.Ref)
+
+	cases := []struct {
+		typ  reflect.Type
+		name string
+	}{
+		{reflect.TypeFor[reflectOrder](), "reflectOrder"},
+		{reflect.TypeFor[reflectPage[string]](), "reflectPageString"},
+		{reflect.TypeFor[reflectPage[*reflectOrder]](), "reflectPageReflectOrder"},
+		{reflect.TypeFor[reflectPage[[]reflectOrder]](), "reflectPageReflectOrderList"},
+		{reflect.TypeFor[reflectPage[[2]int]](), "reflectPageIntList"},
+		{reflect.TypeFor[reflectPage[map[string]reflectOrder]](), "reflectPageMapStringReflectOrder"},
+		{reflect.TypeFor[reflectPage[reflectResult[reflectOrder, time.Time]]](), "reflectPageReflectResultReflectOrderTime"},
+		{reflect.TypeFor[reflectPage[any]](), "reflectPageInterface"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.name, oas.DefaultSchemaNamer(c.typ))
+	}
+
+	// hook para montar o nome dos genéricos
+	of := oas.GenericSchemaNamer(func(base string, args []string) string {
+		return base + "Of" + strings.Join(args, "And")
+	})
+	require.Equal(t, "reflectResultOfReflectOrderAndReflectPageOfString",
+		of(reflect.TypeFor[reflectResult[reflectOrder, reflectPage[string]]]()))
+	b = oas.NewBuilder().WithSchemaNamer(of)
+	require.Equal(t, "#/components/schemas/reflectPageOfReflectOrder", b.SchemaOf(reflect.TypeFor[reflectPage[reflectOrder]]()).Ref.Ref)
+
+	// fora do Builder, $defs usa os mesmos nomes
+	type tree[T any] struct {
+		Value    T          `json:"value"`
+		Children []*tree[T] `json:"children"`
+	}
+	type forest struct {
+		Trees []tree[int] `json:"trees"`
+	}
+	require.Contains(t, oas.SchemaFor[forest]().Defs, "treeInt")
+}
+
+type reflectStatus string
+
+const (
+	reflectStatusActive   reflectStatus = "active"
+	reflectStatusDisabled reflectStatus = "disabled"
+)
+
+func (reflectStatus) OASEnum() []any {
+	return []any{reflectStatusActive, reflectStatusDisabled}
+}
+
+type reflectLevel int
+
+const (
+	reflectLevelLow reflectLevel = iota + 1
+	reflectLevelHigh
+)
+
+type reflectColor uint8
+
+func (*reflectColor) OASEnum() []any { return []any{uint8(1)} }
+
+type reflectEnums struct {
+	Status   reflectStatus    `json:"status"`
+	Previous *reflectStatus   `json:"previous"`
+	Level    reflectLevel     `json:"level"`
+	Levels   []reflectLevel   `json:"levels"`
+	Color    reflectColor     `json:"color"`
+	History  []*reflectStatus `json:"history,omitempty"`
+}
+
+func TestSchemaFor_Enums(t *testing.T) {
+	oas.RegisterEnum[reflectLevel](
+		oas.EnumValue{Value: reflectLevelLow, Name: "LevelLow", Description: "Baixa"},
+		oas.EnumValue{Value: reflectLevelHigh, Name: "LevelHigh"},
+	)
+	defer oas.RegisterEnum[reflectLevel]()
+
+	s := oas.SchemaFor[reflectEnums]()
+	require.Equal(t, oas.Enum{"active", "disabled"}, s.Properties["status"].Schema.Enum)
+	require.Nil(t, s.Properties["status"].Schema.Extensions)
+	previous := s.Properties["previous"].Schema
+	require.Equal(t, []string{"string", "null"}, previous.Type.Many)
+	require.Equal(t, oas.Enum{"active", "disabled", nil}, previous.Enum)
+
+	level := s.Properties["level"].Schema
+	require.Equal(t, oas.Enum{int64(1), int64(2)}, level.Enum)
+	require.Equal(t, []string{"LevelLow", "LevelHigh"}, level.Extensions["x-enum-varnames"])
+	require.Equal(t, []string{"Baixa", ""}, level.Extensions["x-enum-descriptions"])
+	require.Equal(t, oas.Enum{int64(1), int64(2)}, s.Properties["levels"].Schema.Items.Single.Schema.Enum)
+	require.Equal(t, oas.Enum{uint64(1)}, s.Properties["color"].Schema.Enum)
+
+	data, err := json.Marshal(s.Properties["level"])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type": "integer", "format": "int64", "enum": [1, 2],
+		"x-enum-varnames": ["LevelLow", "LevelHigh"], "x-enum-descriptions": ["Baixa", ""]}`, string(data))
+
+	// o registro vale sobre OASEnum e pode ser removido
+	oas.RegisterEnum[reflectStatus]("on")
+	require.Equal(t, oas.Enum{"on"}, oas.SchemaFor[reflectStatus]().Enum)
+	oas.RegisterEnum[reflectStatus]()
+	require.Equal(t, oas.Enum{"active", "disabled"}, oas.SchemaFor[reflectStatus]().Enum)
+
+	v := oas.NewSchemaValidator(nil)
+	require.NoError(t, v.ValidateJSON(oas.SchemaOrRef{Schema: s}, []byte(`{"status": "active", "previous": null, "level": 2, "levels": [1], "color": 1}`)))
+	require.Error(t, v.ValidateJSON(oas.SchemaOrRef{Schema: s}, []byte(`{"status": "on", "previous": null, "level": 3, "levels": [], "color": 1}`)))
+}
+
+type reflectMoney struct {
+	cents int64
+}
+
+func (reflectMoney) OASSchema() oas.Schema {
+	return oas.Schema{Type: oas.TypeString, Pattern: oas.Ptr(`^-?\d+\.\d{2}$`)}
+}
+
+type reflectPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func (*reflectPoint) TransformSchema(s *oas.Schema) {
+	s.Description = oas.Ptr("Coordenadas")
+	s.AdditionalProperties = &oas.AdditionalProperties{Allows: oas.Ptr(false)}
+}
+
+type reflectPriority int
+
+func (reflectPriority) OASEnum() []any { return []any{1, 2} }
+
+func (reflectPriority) TransformSchema(s *oas.Schema) {
+	s.Extensions = s.Extensions.With("x-enum-size", len(s.Enum))
+}
+
+type reflectCustom struct {
+	Price    reflectMoney    `json:"price" oas:"description=Preço"`
+	Discount *reflectMoney   `json:"discount"`
+	Where    reflectPoint    `json:"where"`
+	Path     []reflectPoint  `json:"path"`
+	Priority reflectPriority `json:"priority"`
+}
+
+func TestSchemaFor_Custom(t *testing.T) {
+	s := oas.SchemaFor[reflectCustom]()
+	price := s.Properties["price"].Schema
+	require.Equal(t, "string", *price.Type.One)
+	require.Equal(t, `^-?\d+\.\d{2}$`, *price.Pattern)
+	require.Equal(t, "Preço", *price.Description) // tags do campo vêm depois
+	require.Equal(t, []string{"string", "null"}, s.Properties["discount"].Schema.Type.Many)
+	require.Equal(t, "string", *oas.TypeString.One) // o Type compartilhado não muda
+
+	where := s.Properties["where"].Schema
+	require.Equal(t, "Coordenadas", *where.Description)
+	require.False(t, *where.AdditionalProperties.Allows)
+	require.Len(t, where.Properties, 2)
+	require.Equal(t, "Coordenadas", *s.Properties["path"].Schema.Items.Single.Schema.Description)
+	require.Equal(t, 2, s.Properties["priority"].Schema.Extensions["x-enum-size"])
+
+	// no Builder, o ajuste vale para o componente
+	b := oas.NewBuilder()
+	require.Equal(t, "#/components/schemas/reflectPoint", b.SchemaOf(reflect.TypeFor[reflectPoint]()).Ref.Ref)
+	require.Equal(t, "Coordenadas", *b.Build().Components.Schemas["reflectPoint"].Schema.Description)
+	require.Equal(t, "#/components/schemas/reflectMoney", b.SchemaOf(reflect.TypeFor[reflectMoney]()).Ref.Ref)
+	require.Equal(t, `^-?\d+\.\d{2}$`, *b.Build().Components.Schemas["reflectMoney"].Schema.Pattern)
+}
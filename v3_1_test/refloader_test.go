@@ -0,0 +1,277 @@
+package oas_test
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+const splitRoot = `
+openapi: 3.1.0
+info: {title: API, version: 1.0.0}
+paths:
+  /users:
+    $ref: './paths/users.yaml'
+components:
+  schemas:
+    Local: {type: boolean}
+`
+
+var splitFiles = map[string]string{
+	"api/paths/users.yaml": `
+get:
+  responses:
+    '200':
+      description: ok
+      content:
+        application/json:
+          schema:
+            $ref: '../schemas/user.yaml#/User'
+`,
+	"api/schemas/user.yaml": `
+User:
+  type: object
+  properties:
+    address: {$ref: './address.yaml#/Address'}
+    tag: {$ref: '#/Tag'}
+    example: {$ref: '#/Tag'}
+    local: {$ref: '../openapi.yaml#/components/schemas/Local'}
+    shared: {$ref: '../../shared/common.json'}
+  default: {$ref: literal}
+Tag: {type: string}
+`,
+	"api/schemas/address.yaml": `
+Address: {type: object, required: [street]}
+`,
+	"shared/common.json": `{"type":"integer"}`,
+	"api/cycle/a.yaml":   `A: {$ref: './b.yaml#/B'}`,
+	"api/cycle/b.yaml":   `B: {$ref: './a.yaml#/A'}`,
+}
+
+func splitDoc(t *testing.T) *oas.Document {
+	t.Helper()
+	doc, err := oas.LoadYAML([]byte(splitRoot))
+	require.NoError(t, err)
+	return doc
+}
+
+func assertSplitResolution(t *testing.T, r *oas.Resolver) {
+	t.Helper()
+	doc := splitDoc(t)
+
+	pi, err := r.ResolvePathItem(doc.Paths["/users"])
+	require.NoError(t, err)
+	schemaRef := *pi.Get.Responses["200"].Resp.Content["application/json"].Schema
+	require.Equal(t, "schemas/user.yaml#/User", schemaRef.Ref.Ref)
+
+	user, err := r.ResolveSchema(schemaRef)
+	require.NoError(t, err)
+	require.Equal(t, "object", *user.Type.One)
+
+	// refs aninhados foram reescritos relativos ao documento raiz
+	require.Equal(t, "schemas/address.yaml#/Address", user.Properties["address"].Ref.Ref)
+	require.Equal(t, "schemas/user.yaml#/Tag", user.Properties["tag"].Ref.Ref)
+	require.Equal(t, "schemas/user.yaml#/Tag", user.Properties["example"].Ref.Ref)
+	require.Equal(t, "#/components/schemas/Local", user.Properties["local"].Ref.Ref)
+	require.Equal(t, "../shared/common.json", user.Properties["shared"].Ref.Ref)
+	// valores literais não são tocados
+	require.Equal(t, map[string]any{"$ref": "literal"}, user.Default)
+
+	addr, err := r.ResolveSchema(user.Properties["address"])
+	require.NoError(t, err)
+	require.Equal(t, []string{"street"}, []string(addr.Required))
+
+	tag, err := r.ResolveSchema(user.Properties["tag"])
+	require.NoError(t, err)
+	require.Equal(t, "string", *tag.Type.One)
+
+	local, err := r.ResolveSchema(user.Properties["local"])
+	require.NoError(t, err)
+	require.Equal(t, "boolean", *local.Type.One)
+
+	shared, err := r.ResolveSchema(user.Properties["shared"])
+	require.NoError(t, err)
+	require.Equal(t, "integer", *shared.Type.One)
+
+	_, err = r.ResolveSchema(oas.SchemaOrRef{Ref: ref("./cycle/a.yaml#/A")})
+	require.ErrorIs(t, err, oas.ErrRefCycle)
+}
+
+func TestResolver_External_MapLoader(t *testing.T) {
+	files := oas.MapLoader{}
+	for name, content := range splitFiles {
+		files[name] = []byte(content)
+	}
+	calls := map[string]int{}
+	loader := oas.RefLoaderFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		calls[uri]++
+		return files.Load(ctx, uri)
+	})
+
+	r := oas.NewResolver(splitDoc(t), oas.WithBaseURI("api/openapi.yaml"), oas.WithRefLoader(loader))
+	assertSplitResolution(t, r)
+	assertSplitResolution(t, r)
+
+	// cache: cada documento é buscado uma única vez
+	for uri, n := range calls {
+		require.Equal(t, 1, n, uri)
+	}
+	require.Equal(t, 1, calls["api/schemas/user.yaml"])
+}
+
+func TestResolver_External_FSLoader(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, content := range splitFiles {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	r := oas.NewResolver(splitDoc(t), oas.WithBaseURI("api/openapi.yaml"), oas.WithRefLoader(oas.FSLoader{FS: fsys}))
+	assertSplitResolution(t, r)
+}
+
+func TestResolver_External_FileLoader(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range splitFiles {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+
+	// caminho relativo a Dir
+	r := oas.NewResolver(splitDoc(t), oas.WithBaseURI("api/openapi.yaml"), oas.WithRefLoader(oas.FileLoader{Dir: dir}))
+	assertSplitResolution(t, r)
+
+	// caminho absoluto
+	abs := filepath.Join(dir, "api", "openapi.yaml")
+	r = oas.NewResolver(splitDoc(t), oas.WithBaseURI(abs), oas.WithRefLoader(oas.FileLoader{}))
+	s, err := r.ResolveSchema(oas.SchemaOrRef{Ref: ref("schemas/address.yaml#/Address")})
+	require.NoError(t, err)
+	require.Equal(t, "object", *s.Type.One)
+
+	// URI file://
+	r = oas.NewResolver(splitDoc(t), oas.WithBaseURI("file://"+filepath.ToSlash(abs)), oas.WithRefLoader(oas.FileLoader{}))
+	s, err = r.ResolveSchema(oas.SchemaOrRef{Ref: ref("schemas/address.yaml#/Address")})
+	require.NoError(t, err)
+	require.Equal(t, "object", *s.Type.One)
+}
+
+func TestResolver_External_HTTPLoader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		content, ok := splitFiles[req.URL.Path[1:]]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	r := oas.NewResolver(splitDoc(t),
+		oas.WithBaseURI(srv.URL+"/api/openapi.yaml"),
+		oas.WithRefLoader(oas.HTTPLoader{Client: srv.Client()}),
+		oas.WithContext(context.Background()),
+	)
+	pi, err := r.ResolvePathItem(splitDoc(t).Paths["/users"])
+	require.NoError(t, err)
+	user, err := r.ResolveSchema(*pi.Get.Responses["200"].Resp.Content["application/json"].Schema)
+	require.NoError(t, err)
+
+	// refs aninhados viram URLs absolutas
+	require.Equal(t, srv.URL+"/api/schemas/address.yaml#/Address", user.Properties["address"].Ref.Ref)
+	addr, err := r.ResolveSchema(user.Properties["address"])
+	require.NoError(t, err)
+	require.Equal(t, "object", *addr.Type.One)
+	local, err := r.ResolveSchema(user.Properties["local"])
+	require.NoError(t, err)
+	require.Equal(t, "boolean", *local.Type.One)
+
+	// 404
+	_, err = r.ResolveSchema(oas.SchemaOrRef{Ref: ref("missing.yaml#/X")})
+	require.ErrorContains(t, err, "status 404")
+}
+
+func TestHTTPLoader_MaxBytes(t *testing.T) {
+	body := strings.Repeat("a", 64)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	data, err := oas.HTTPLoader{Client: srv.Client(), MaxBytes: 64}.Load(ctx, srv.URL)
+	require.NoError(t, err)
+	require.Len(t, data, 64)
+	_, err = oas.HTTPLoader{Client: srv.Client(), MaxBytes: 63}.Load(ctx, srv.URL)
+	require.ErrorContains(t, err, "maior que 63 bytes")
+
+	// sem MaxBytes vale DefaultHTTPMaxBytes
+	body = strings.Repeat("a", oas.DefaultHTTPMaxBytes+1)
+	_, err = oas.HTTPLoader{Client: srv.Client()}.Load(ctx, srv.URL)
+	require.ErrorContains(t, err, "documento maior que")
+}
+
+func TestRefLoaders_Errors(t *testing.T) {
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	// sem loader
+	r := oas.NewResolver(splitDoc(t))
+	_, err := r.ResolveSchema(oas.SchemaOrRef{Ref: ref("schemas/user.yaml#/User")})
+	require.ErrorIs(t, err, oas.ErrExternalRef)
+
+	// base inválida
+	r = oas.NewResolver(splitDoc(t), oas.WithBaseURI("http://[::1"))
+	_, err = r.ResolveSchema(oas.SchemaOrRef{Ref: ref("#/components/schemas/Local")})
+	require.Error(t, err)
+
+	// documento externo inválido
+	r = oas.NewResolver(splitDoc(t), oas.WithRefLoader(oas.MapLoader{
+		"bad.yaml": []byte("a: ["),
+		"bad.json": []byte("{"),
+	}))
+	_, err = r.ResolveSchema(oas.SchemaOrRef{Ref: ref("bad.yaml#/a")})
+	require.Error(t, err)
+	_, err = r.ResolveSchema(oas.SchemaOrRef{Ref: ref("bad.json#/a")})
+	require.Error(t, err)
+	_, err = r.ResolveSchema(oas.SchemaOrRef{Ref: ref("missing.json")})
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// MapLoader
+	_, err = oas.MapLoader{}.Load(canceled, "a.yaml")
+	require.ErrorIs(t, err, context.Canceled)
+	b, err := oas.MapLoader{"a.yaml": []byte("x")}.Load(ctx, "./a.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "x", string(b))
+
+	// FileLoader
+	_, err = oas.FileLoader{}.Load(canceled, "a.yaml")
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = oas.FileLoader{}.Load(ctx, "https://example.com/a.yaml")
+	require.Error(t, err)
+	_, err = oas.FileLoader{}.Load(ctx, "file://%zz")
+	require.Error(t, err)
+
+	// FSLoader
+	_, err = oas.FSLoader{FS: fstest.MapFS{}}.Load(canceled, "a.yaml")
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = oas.FSLoader{FS: fstest.MapFS{}}.Load(ctx, "https://example.com/a.yaml")
+	require.Error(t, err)
+
+	// HTTPLoader
+	_, err = oas.HTTPLoader{}.Load(ctx, "ftp://example.com/a.yaml")
+	require.Error(t, err)
+	_, err = oas.HTTPLoader{}.Load(ctx, "http://[::1")
+	require.Error(t, err)
+	_, err = oas.HTTPLoader{}.Load(canceled, "http://127.0.0.1:1/a.yaml")
+	require.ErrorIs(t, err, context.Canceled)
+}
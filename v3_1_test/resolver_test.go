@@ -0,0 +1,239 @@
+package oas_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+const resolverYAML = `
+openapi: 3.1.0
+info: {title: API, version: 1.0.0}
+paths:
+  /users:
+    get:
+      parameters:
+        - name: q
+          in: query
+      responses:
+        '200':
+          description: ok
+  /users/{id}:
+    $ref: '#/components/pathItems/User'
+components:
+  schemas:
+    User:
+      type: object
+    Alias:
+      $ref: '#/components/schemas/User'
+    Loop:
+      $ref: '#/components/schemas/Loop'
+    a/b~c:
+      type: string
+  parameters:
+    Id: {name: id, in: path, required: true}
+  requestBodies:
+    Body:
+      content: {}
+  responses:
+    NotFound: {description: não encontrado}
+  headers:
+    Rate: {description: limite}
+  examples:
+    One: {summary: um}
+  links:
+    Self: {operationId: getUser}
+  callbacks:
+    Hook:
+      '{$request.body#/url}':
+        post:
+          responses: {'200': {description: ok}}
+  securitySchemes:
+    Bearer: {type: http, scheme: bearer}
+  pathItems:
+    User:
+      get:
+        responses: {'200': {description: ok}}
+`
+
+func loadResolverDoc(t *testing.T) *oas.Document {
+	t.Helper()
+	doc, err := oas.Load(context.Background(), oas.FromBytes([]byte(resolverYAML)))
+	require.NoError(t, err)
+	return doc
+}
+
+func ref(r string) *oas.Reference {
+	return &oas.Reference{Ref: r}
+}
+
+func TestResolver_Components(t *testing.T) {
+	r := oas.NewResolver(loadResolverDoc(t))
+
+	s, err := r.ResolveSchema(oas.SchemaOrRef{Ref: ref("#/components/schemas/User")})
+	require.NoError(t, err)
+	require.Equal(t, "object", *s.Type.One)
+
+	// cadeia de refs
+	s, err = r.ResolveSchema(oas.SchemaOrRef{Ref: ref("#/components/schemas/Alias")})
+	require.NoError(t, err)
+	require.Equal(t, "object", *s.Type.One)
+
+	// escapes ~0 e ~1
+	s, err = r.ResolveSchema(oas.SchemaOrRef{Ref: ref("#/components/schemas/a~1b~0c")})
+	require.NoError(t, err)
+	require.Equal(t, "string", *s.Type.One)
+
+	// irmãos de $ref ficam ao lado, com o alvo em allOf
+	s, err = r.ResolveSchema(oas.SchemaOrRef{Ref: ref("#/components/schemas/Alias"), Schema: &oas.Schema{Description: oas.Ptr("dono")}})
+	require.NoError(t, err)
+	require.Equal(t, "dono", *s.Description)
+	require.Len(t, s.AllOf, 1)
+	require.Equal(t, "object", *s.AllOf[0].Schema.Type.One)
+
+	// schemas booleanos
+	s, err = r.ResolveSchema(oas.SchemaOrRef{Bool: oas.Ptr(true)})
+	require.NoError(t, err)
+	require.Equal(t, oas.Schema{}, *s)
+	s, err = r.ResolveSchema(oas.SchemaOrRef{Bool: oas.Ptr(false)})
+	require.NoError(t, err)
+	require.Len(t, s.Not, 1)
+
+	// valor sem ref é devolvido como está
+	inline := &oas.Schema{Type: oas.TypeInteger}
+	s, err = r.ResolveSchema(oas.SchemaOrRef{Schema: inline})
+	require.NoError(t, err)
+	require.Same(t, inline, s)
+
+	p, err := r.ResolveParameter(oas.ParameterOrRef{Ref: ref("#/components/parameters/Id")})
+	require.NoError(t, err)
+	require.Equal(t, oas.InPath, p.In)
+
+	rb, err := r.ResolveRequestBody(oas.RequestBodyOrRef{Ref: ref("#/components/requestBodies/Body")})
+	require.NoError(t, err)
+	require.NotNil(t, rb)
+
+	resp, err := r.ResolveResponse(oas.ResponseOrRef{Ref: ref("#/components/responses/NotFound")})
+	require.NoError(t, err)
+	require.Equal(t, "não encontrado", resp.Description)
+
+	h, err := r.ResolveHeader(oas.HeaderOrRef{Ref: ref("#/components/headers/Rate")})
+	require.NoError(t, err)
+	require.Equal(t, "limite", *h.Description)
+
+	ex, err := r.ResolveExample(oas.ExampleOrRef{Ref: ref("#/components/examples/One")})
+	require.NoError(t, err)
+	require.Equal(t, "um", *ex.Summary)
+
+	l, err := r.ResolveLink(oas.LinkOrRef{Ref: ref("#/components/links/Self")})
+	require.NoError(t, err)
+	require.Equal(t, "getUser", *l.OperationID)
+
+	cb, err := r.ResolveCallback(oas.CallbackOrRef{Ref: ref("#/components/callbacks/Hook")})
+	require.NoError(t, err)
+	require.Contains(t, *cb, "{$request.body#/url}")
+
+	sec, err := r.ResolveSecurityScheme(oas.SecuritySchemeOrRef{Ref: ref("#/components/securitySchemes/Bearer")})
+	require.NoError(t, err)
+	require.Equal(t, oas.SecHTTP, sec.Type)
+
+	pi, err := r.ResolvePathItem(oas.PathItemOrRef{Ref: ref("#/components/pathItems/User")})
+	require.NoError(t, err)
+	require.NotNil(t, pi.Get)
+}
+
+func TestResolver_ReferenceOverrides(t *testing.T) {
+	r := oas.NewResolver(loadResolverDoc(t))
+	override := func(target string, summary, description *string) *oas.Reference {
+		return &oas.Reference{Ref: target, Summary: summary, Description: description}
+	}
+
+	// summary e description substituem os do alvo
+	ex, err := r.ResolveExample(oas.ExampleOrRef{Ref: override("#/components/examples/One", oas.Ptr("outro"), oas.Ptr("detalhe"))})
+	require.NoError(t, err)
+	require.Equal(t, "outro", *ex.Summary)
+	require.Equal(t, "detalhe", *ex.Description)
+
+	resp, err := r.ResolveResponse(oas.ResponseOrRef{Ref: override("#/components/responses/NotFound", nil, oas.Ptr("usuário não encontrado"))})
+	require.NoError(t, err)
+	require.Equal(t, "usuário não encontrado", resp.Description)
+
+	pi, err := r.ResolvePathItem(oas.PathItemOrRef{Ref: override("#/components/pathItems/User", oas.Ptr("usuário"), nil)})
+	require.NoError(t, err)
+	require.Equal(t, "usuário", *pi.Summary)
+	require.Nil(t, pi.Description)
+
+	// summary não se aplica a Header: sem efeito
+	h, err := r.ResolveHeader(oas.HeaderOrRef{Ref: override("#/components/headers/Rate", oas.Ptr("x"), nil)})
+	require.NoError(t, err)
+	require.Equal(t, "limite", *h.Description)
+
+	// numa cadeia, o $ref mais externo prevalece
+	doc := loadResolverDoc(t)
+	doc.Components.Parameters["Alias"] = oas.ParameterOrRef{Ref: override("#/components/parameters/Id", nil, oas.Ptr("interno"))}
+	doc.Components.Parameters["Outer"] = oas.ParameterOrRef{Ref: override("#/components/parameters/Alias", nil, oas.Ptr("externo"))}
+	r = oas.NewResolver(doc)
+	p, err := r.ResolveParameter(oas.ParameterOrRef{Ref: ref("#/components/parameters/Outer")})
+	require.NoError(t, err)
+	require.Equal(t, "externo", *p.Description)
+	p, err = r.ResolveParameter(oas.ParameterOrRef{Ref: ref("#/components/parameters/Alias")})
+	require.NoError(t, err)
+	require.Equal(t, "interno", *p.Description)
+
+	// Dereference também aplica
+	doc.Paths["/users"].PathItem.Get.Parameters = []oas.ParameterOrRef{{Ref: ref("#/components/parameters/Outer")}}
+	out, _, err := oas.Dereference(doc, oas.DereferenceOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "externo", *out.Paths["/users"].PathItem.Get.Parameters[0].Param.Description)
+	require.Nil(t, out.Components.Parameters["Id"].Param.Description)
+}
+
+func TestResolver_Paths(t *testing.T) {
+	r := oas.NewResolver(loadResolverDoc(t))
+
+	p, err := r.ResolveParameter(oas.ParameterOrRef{Ref: ref("#/paths/~1users/get/parameters/0")})
+	require.NoError(t, err)
+	require.Equal(t, "q", p.Name)
+
+	// fragmento com percent-encoding; o path item é outro $ref e a cadeia é seguida
+	pi, err := r.ResolvePathItem(oas.PathItemOrRef{Ref: ref("#/paths/~1users~1%7Bid%7D")})
+	require.NoError(t, err)
+	require.NotNil(t, pi.Get)
+}
+
+func TestResolver_Errors(t *testing.T) {
+	r := oas.NewResolver(loadResolverDoc(t))
+
+	cases := []struct {
+		ref string
+		err error
+	}{
+		{"#/components/schemas/Missing", oas.ErrRefNotFound},
+		{"#/components/schemas/Loop", oas.ErrRefCycle},
+		{"schemas.yaml#/User", oas.ErrExternalRef},
+		{"#components", oas.ErrInvalidPointer},
+		{"#/components/schemas/a~2b", oas.ErrInvalidPointer},
+		{"#/components/schemas/~~01", oas.ErrInvalidPointer},
+		{"#/components/schemas/a~", oas.ErrInvalidPointer},
+		{"#/a%zz", oas.ErrInvalidPointer},
+		{"#/paths/~1users/get/parameters/9", oas.ErrRefNotFound},
+		{"#/paths/~1users/get/parameters/x", oas.ErrRefNotFound},
+		{"#/paths/~1users/get/parameters/00", oas.ErrRefNotFound},
+		{"#/openapi/x", oas.ErrRefNotFound},
+	}
+	for _, c := range cases {
+		_, err := r.ResolveSchema(oas.SchemaOrRef{Ref: ref(c.ref)})
+		require.ErrorIs(t, err, c.err, c.ref)
+		var re *oas.RefError
+		require.ErrorAs(t, err, &re)
+		require.Equal(t, c.ref, re.Ref)
+		require.Contains(t, err.Error(), c.ref)
+	}
+
+	// alvo com tipo incompatível
+	_, err := r.ResolveParameter(oas.ParameterOrRef{Ref: ref("#/openapi")})
+	require.Error(t, err)
+}
@@ -0,0 +1,125 @@
+package oas_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+const sourceMapYAML = `openapi: 3.1.0
+info:
+  title: API
+  version: 1.0.0
+defaults: &defaults
+  description: ok
+paths:
+  /users/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          schema: {type: string}
+      responses:
+        '200':
+          <<: *defaults
+        '404':
+          $ref: '#/components/responses/Missing'
+`
+
+func TestSourceMap_YAML(t *testing.T) {
+	doc, err := oas.Load(context.Background(), oas.FromBytes([]byte(sourceMapYAML), "openapi.yaml"), oas.WithSourceMap())
+	require.NoError(t, err)
+	sm := doc.SourceMap()
+
+	require.Equal(t, oas.Position{File: "openapi.yaml", Line: 1, Column: 1}, sm[""])
+	require.Equal(t, oas.Position{File: "openapi.yaml", Line: 3, Column: 3}, sm["/info/title"])
+	require.Equal(t, oas.Position{File: "openapi.yaml", Line: 11, Column: 11}, sm["/paths/~1users~1{id}/get/parameters/0"])
+	require.Equal(t, "openapi.yaml:12:11", sm["/paths/~1users~1{id}/get/parameters/0/in"].String())
+	// chave herdada via merge aponta para a definição da âncora
+	require.Equal(t, oas.Position{File: "openapi.yaml", Line: 6, Column: 3}, sm["/paths/~1users~1{id}/get/responses/200/description"])
+
+	// ausente: cai no ancestral mais próximo
+	pos, ok := sm.Lookup("/info/summary")
+	require.True(t, ok)
+	require.Equal(t, "openapi.yaml:2:1", pos.String())
+	_, ok = oas.SourceMap{}.Lookup("/x")
+	require.False(t, ok)
+
+	// erros de Validate levam a posição
+	err = doc.Validate()
+	var list oas.ValidationErrors
+	require.ErrorAs(t, err, &list)
+	require.Len(t, list, 2)
+	require.Equal(t, "openapi.yaml:11:11: /paths/~1users~1{id}/get/parameters/0/required: parâmetro de path deve ter required: true", list[0].Error())
+	require.Equal(t, "openapi.yaml:17:9", list[1].Position.String())
+	require.Contains(t, list[1].Error(), "openapi.yaml:17:9: /paths/~1users~1{id}/get/responses/404: $ref")
+
+	// LoadYAML também, sem nome de arquivo
+	doc, err = oas.LoadYAML([]byte(sourceMapYAML), oas.WithSourceMap())
+	require.NoError(t, err)
+	require.Equal(t, "3:3", doc.SourceMap()["/info/title"].String())
+
+	// sem a opção não há source map
+	doc, err = oas.LoadYAML([]byte(sourceMapYAML))
+	require.NoError(t, err)
+	require.Nil(t, doc.SourceMap())
+	require.ErrorAs(t, doc.Validate(), &list)
+	require.Nil(t, list[0].Position)
+}
+
+func TestSourceMap_JSON(t *testing.T) {
+	data := []byte(`{
+  "openapi": "3.1.0",
+  "info": {"title": "Ação", "version": "1"},
+  "paths": {
+    "/a": {"get": {"tags": ["x", "y"], "responses": {"200": {"description": "ok"}}}}
+  }
+}`)
+	doc, err := oas.Load(context.Background(), oas.FromBytes(data, "openapi.json"), oas.WithSourceMap())
+	require.NoError(t, err)
+	sm := doc.SourceMap()
+	require.Equal(t, "openapi.json:1:1", sm[""].String())
+	require.Equal(t, "openapi.json:2:3", sm["/openapi"].String())
+	// colunas contam runas, não bytes
+	require.Equal(t, "openapi.json:3:29", sm["/info/version"].String())
+	require.Equal(t, "openapi.json:5:34", sm["/paths/~1a/get/tags/1"].String())
+	require.Equal(t, "openapi.json:5:40", sm["/paths/~1a/get/responses"].String())
+}
+
+func TestSourceMap_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	// erro de decodificação
+	_, err := oas.Load(ctx, oas.FromBytes([]byte("openapi: 3.1.0\ninfo:\n  title: [x]\n"), "api.yaml"), oas.WithSourceMap())
+	var ve *oas.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, "api.yaml:3:3", ve.Position.String())
+	require.Contains(t, err.Error(), "api.yaml:3:3: /info/title: ")
+
+	// erro de Bundle/Dereference
+	doc, err := oas.Load(ctx, oas.FromBytes([]byte(`
+openapi: 3.1.0
+paths:
+  /x:
+    $ref: './missing.yaml'
+`), "api.yaml"), oas.WithSourceMap())
+	require.NoError(t, err)
+	_, err = oas.Bundle(doc, oas.WithRefLoader(oas.MapLoader{}))
+	var re *oas.RefError
+	require.ErrorAs(t, err, &re)
+	require.Equal(t, "/paths/~1x", re.Pointer)
+	require.Equal(t, "api.yaml:4:3", re.Position.String())
+	require.Contains(t, err.Error(), `api.yaml:4:3: $ref "./missing.yaml"`)
+
+	doc.Paths["/x"] = oas.PathItemOrRef{Ref: ref("#/components/pathItems/Missing")}
+	_, _, err = oas.Dereference(doc, oas.DereferenceOptions{})
+	require.ErrorAs(t, err, &re)
+	require.Equal(t, "api.yaml:4:3", re.Position.String())
+
+	// YAML inválido com a opção
+	_, err = oas.LoadYAML([]byte("a: [1\n"), oas.WithSourceMap())
+	require.Error(t, err)
+}
@@ -62,6 +62,74 @@ func TestSchemaOrRef_JSON(t *testing.T) {
 		err := json.Unmarshal([]byte(`123`), &sr)
 		require.Error(t, err)
 	}
+	// schema booleano
+	{
+		var sr oas.SchemaOrRef
+		require.NoError(t, json.Unmarshal([]byte(`false`), &sr))
+		require.False(t, *sr.Bool)
+		require.Nil(t, sr.Schema)
+		out, _ := json.Marshal(sr)
+		require.Equal(t, `false`, string(out))
+	}
+	// $ref com irmãos: os dois lados são preservados, $ref primeiro
+	{
+		var sr oas.SchemaOrRef
+		require.NoError(t, json.Unmarshal([]byte(`{"description":"dono","$ref":"#/components/schemas/User","maximum":9007199254740993}`), &sr))
+		require.Equal(t, "#/components/schemas/User", sr.Ref.Ref)
+		require.Equal(t, "dono", *sr.Schema.Description)
+		out, _ := json.Marshal(sr)
+		require.Equal(t, `{"$ref":"#/components/schemas/User","description":"dono","maximum":9007199254740992}`, string(out))
+
+		sr.Schema = &oas.Schema{}
+		out, _ = json.Marshal(sr)
+		require.Equal(t, `{"$ref":"#/components/schemas/User"}`, string(out))
+	}
+	// items: false (prefixItems fechado)
+	{
+		var s oas.Schema
+		require.NoError(t, json.Unmarshal([]byte(`{"prefixItems":[true],"items":false}`), &s))
+		require.False(t, *s.Items.Single.Bool)
+		require.True(t, *s.PrefixItems[0].Bool)
+		out, _ := json.Marshal(s)
+		require.JSONEq(t, `{"prefixItems":[true],"items":false}`, string(out))
+	}
+}
+
+func TestSchema_2020Keywords_JSON(t *testing.T) {
+	in := `{
+		"$id": "https://example.com/user",
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$anchor": "user",
+		"$dynamicAnchor": "node",
+		"$dynamicRef": "#node",
+		"$defs": {"name": {"type": "string"}},
+		"$comment": "interno",
+		"if": {"required": ["kind"]},
+		"then": {"$ref": "#/$defs/name"},
+		"else": {"type": "object"},
+		"dependentSchemas": {"card": {"required": ["cvv"]}},
+		"dependentRequired": {"billing": ["address", "zip"]},
+		"propertyNames": {"pattern": "^[a-z]+$"},
+		"unevaluatedProperties": false,
+		"unevaluatedItems": {"type": "integer"},
+		"contentEncoding": "base64",
+		"contentMediaType": "application/json",
+		"contentSchema": {"type": "object"},
+		"externalDocs": {"url": "https://example.com/docs"},
+		"example": {"name": "ana"}
+	}`
+	var s oas.Schema
+	require.NoError(t, json.Unmarshal([]byte(in), &s))
+	require.Equal(t, "https://example.com/user", *s.ID)
+	require.Equal(t, "#/$defs/name", s.Then.Ref.Ref)
+	require.Equal(t, []string{"address", "zip"}, s.DependentRequired["billing"])
+	require.False(t, *s.UnevaluatedProperties.Allows)
+	require.NotNil(t, s.UnevaluatedItems.Schema)
+	require.Equal(t, map[string]any{"name": "ana"}, s.Example)
+
+	out, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, in, string(out))
 }
 
 func TestItems_JSON(t *testing.T) {
@@ -129,6 +197,16 @@ func TestPathItemOrRef_JSON(t *testing.T) {
 		out, _ := json.Marshal(pir)
 		require.Contains(t, string(out), `"$ref"`)
 	}
+	// ref com summary e description
+	{
+		var pir oas.PathItemOrRef
+		in := `{"$ref":"#/components/pathItems/Foo","summary":"s","description":"d"}`
+		require.NoError(t, json.Unmarshal([]byte(in), &pir))
+		require.Equal(t, "s", *pir.Ref.Summary)
+		require.Equal(t, "d", *pir.Ref.Description)
+		out, _ := json.Marshal(pir)
+		require.Equal(t, in, string(out))
+	}
 	// válido
 	{
 		var pir oas.PathItemOrRef
@@ -0,0 +1,477 @@
+package oas_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+const validYAML = `
+openapi: 3.1.0
+info:
+  title: API
+  version: 1.0.0
+  license: {name: MIT, identifier: MIT}
+servers:
+  - url: 'https://{env}.example.com'
+    variables:
+      env: {default: api, enum: [api, sandbox]}
+paths:
+  /users/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: string}}
+    get:
+      operationId: getUser
+      security: [{oauth: [read]}]
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+          links:
+            self: {operationId: getUser}
+        4XX: {$ref: '#/components/responses/Error'}
+components:
+  schemas:
+    User:
+      type: [object, 'null']
+      required: [name]
+      properties:
+        name: {type: string, minLength: 1, maxLength: 10}
+  responses:
+    Error: {description: erro}
+  securitySchemes:
+    oauth:
+      type: oauth2
+      flows:
+        authorizationCode:
+          authorizationUrl: https://auth.example.com/authorize
+          tokenUrl: https://auth.example.com/token
+          scopes: {read: leitura}
+    key: {type: apiKey, name: X-Key, in: header}
+    basic: {type: http, scheme: basic}
+`
+
+func TestDocument_Validate(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(validYAML))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate())
+}
+
+func TestDocument_UnusedComponents(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(`
+openapi: 3.1.0
+info: {title: API, version: '1'}
+paths:
+  /pets:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                oneOf: [{$ref: '#/components/schemas/Cat'}, {$ref: '#/components/schemas/Dog'}]
+                discriminator: {propertyName: kind, mapping: {cat: Cat, dog: '#/components/schemas/Dog'}}
+  /next: {$ref: '#/components/pathItems/Next'}
+components:
+  schemas:
+    Cat: {type: object}
+    Dog: {type: object, properties: {owner: {$ref: '#/components/schemas/Owner/properties/name'}}}
+    Owner: {type: object, properties: {name: {type: string}}}
+    Orphan: {type: object}
+  pathItems:
+    Next: {get: {responses: {'200': {description: ok}}}}
+  securitySchemes:
+    key: {type: apiKey, name: X-Key, in: header}
+`))
+	require.NoError(t, err)
+
+	// componentes sem uso não invalidam o documento
+	require.NoError(t, doc.Validate())
+	list := doc.UnusedComponents()
+	require.False(t, list.HasErrors(), list.Error())
+	require.Equal(t, oas.ValidationErrors{
+		{Pointer: "/components/schemas/Orphan", Code: oas.CodeUnused, Severity: oas.SeverityWarning, Message: "componente não usado"},
+		{Pointer: "/components/securitySchemes/key", Code: oas.CodeUnused, Severity: oas.SeverityWarning, Message: "componente não usado"},
+	}, list)
+
+	// documento só com components é uma biblioteca: nada a avisar
+	doc.Paths = nil
+	require.Nil(t, doc.UnusedComponents())
+}
+
+func TestDocument_ValidateErrors(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(`
+openapi: 3.1.0
+info:
+  title: ''
+  license: {name: MIT, identifier: MIT, url: https://mit.edu}
+servers:
+  - url: 'https://{env}.{region}.example.com'
+    variables:
+      env: {default: prod, enum: [api, sandbox]}
+paths:
+  users/{id}:
+    get:
+      parameters:
+        - {name: id, in: path, schema: {type: string}}
+        - {name: q, in: body, schema: {type: string}}
+        - {name: h, in: header, allowEmptyValue: true}
+        - $ref: '#/components/parameters/Missing'
+      security: [{unknown: []}]
+      responses:
+        '200': {}
+        '600': {description: x}
+        default:
+          description: ok
+          links:
+            l: {}
+components:
+  schemas:
+    'bad name': {type: text}
+    Range: {minLength: 5, maxLength: 1, multipleOf: 0, pattern: '('}
+    Keywords:
+      $anchor: '1st'
+      $defs:
+        inner: {type: text}
+      dependentRequired: {a: [b, b]}
+      if: {type: text}
+    Sibling: {$ref: '#/components/schemas/Range', type: text}
+  securitySchemes:
+    key: {type: apiKey}
+    web: {type: http}
+    oauth:
+      type: oauth2
+      flows:
+        implicit: {tokenUrl: https://x, scopes: {}}
+        password: {scopes: {}}
+        authorizationCode: {authorizationUrl: https://x}
+    oidc: {type: openIdConnect}
+    what: {type: magic}
+`))
+	require.NoError(t, err)
+
+	err = doc.Validate()
+	require.Error(t, err)
+	msg := err.Error()
+	for _, want := range []string{
+		"/info/title: campo obrigatório",
+		"/info/version: campo obrigatório",
+		"/info/license: identifier e url são mutuamente exclusivos",
+		"/servers/0/url: variável {region} não declarada",
+		`/servers/0/variables/env/default: default "prod" não está em enum`,
+		"/paths/users~1{id}: path deve começar com /",
+		"/paths/users~1{id}/get/parameters/0/required: parâmetro de path deve ter required: true",
+		`/paths/users~1{id}/get/parameters/1/in: valor "body" inválido`,
+		"/paths/users~1{id}/get/parameters/2/allowEmptyValue: permitido apenas em parâmetros de query",
+		"/paths/users~1{id}/get/parameters/2: schema ou content é obrigatório",
+		"/paths/users~1{id}/get/parameters/3: $ref",
+		`/paths/users~1{id}/get/security/0/unknown: security scheme "unknown" não declarado`,
+		"/paths/users~1{id}/get/responses/200/description: campo obrigatório",
+		`/paths/users~1{id}/get/responses/600: código de status inválido "600"`,
+		"/paths/users~1{id}/get/responses/default/links/l: exatamente um entre operationRef e operationId",
+		"/components/schemas/bad name: nome de componente inválido",
+		`/components/schemas/bad name/type: tipo "text" inválido`,
+		"/components/schemas/Range/minLength: minLength maior que maxLength",
+		"/components/schemas/Range/multipleOf: deve ser > 0",
+		"/components/schemas/Range/pattern: regex inválida",
+		`/components/schemas/Keywords/$anchor: âncora "1st" inválida`,
+		`/components/schemas/Keywords/$defs/inner/type: tipo "text" inválido`,
+		`/components/schemas/Keywords/dependentRequired/a: propriedade "b" repetida`,
+		`/components/schemas/Keywords/if/type: tipo "text" inválido`,
+		`/components/schemas/Sibling/type: tipo "text" inválido`,
+		"/components/securitySchemes/key/name: obrigatório para apiKey",
+		"/components/securitySchemes/key/in: apiKey exige in query, header ou cookie",
+		"/components/securitySchemes/web/scheme: obrigatório para http",
+		"/components/securitySchemes/oauth/flows/implicit/authorizationUrl: campo obrigatório",
+		"/components/securitySchemes/oauth/flows/implicit/tokenUrl: não se aplica ao fluxo implicit",
+		"/components/securitySchemes/oauth/flows/password/tokenUrl: campo obrigatório",
+		"/components/securitySchemes/oauth/flows/authorizationCode/tokenUrl: campo obrigatório",
+		"/components/securitySchemes/oauth/flows/authorizationCode/scopes: campo obrigatório",
+		"/components/securitySchemes/oidc/openIdConnectUrl: obrigatório para openIdConnect",
+		`/components/securitySchemes/what/type: tipo "magic" inválido`,
+	} {
+		require.Contains(t, msg, want)
+	}
+
+	// documento vazio
+	err = (&oas.Document{}).Validate()
+	require.Error(t, err)
+	for _, want := range []string{
+		"/openapi: campo obrigatório",
+		"(raiz): ao menos um entre paths, webhooks e components é obrigatório",
+	} {
+		require.Contains(t, err.Error(), want)
+	}
+
+	// versão fora de 3.1
+	doc = &oas.Document{OpenAPI: "3.0.3", Info: oas.Info{Title: "API", Version: "1"}, Paths: oas.Paths{}}
+	require.EqualError(t, doc.Validate(), `/openapi: versão "3.0.3" não é 3.1.x`)
+
+	// cada problema em sua própria linha
+	require.Len(t, strings.Split((&oas.Document{}).Validate().Error(), "\n"), 4)
+}
+
+func TestDocument_ValidatePatterns(t *testing.T) {
+	doc := &oas.Document{OpenAPI: "3.1.0", Info: oas.Info{Title: "API", Version: "1"}, Components: &oas.Components{
+		Schemas: map[string]oas.SchemaOrRef{"A": {Schema: &oas.Schema{
+			Pattern: oas.Ptr(`^(?=.*\d)\w+$`),
+			PatternProperties: map[string]oas.SchemaOrRef{
+				`^(a)\1$`:  {Schema: &oas.Schema{}},
+				`^(?!x-)`:  {Schema: &oas.Schema{}},
+				`^[a-z]+$`: {Schema: &oas.Schema{}},
+			},
+		}}},
+	}}
+	// ECMA-262 válido que o RE2 não aceita não invalida o documento
+	require.NoError(t, doc.Validate())
+
+	// com algum erro, os avisos vêm junto, em ordem estável
+	doc.Components.Schemas["B"] = oas.SchemaOrRef{Schema: &oas.Schema{Pattern: oas.Ptr("(")}}
+	for range 5 {
+		var list oas.ValidationErrors
+		require.ErrorAs(t, doc.Validate(), &list)
+		var got []string
+		for _, e := range list {
+			got = append(got, string(e.Severity)+" "+e.Pointer)
+		}
+		require.Equal(t, []string{
+			"warning /components/schemas/A/pattern",
+			"warning /components/schemas/A/patternProperties/^(?!x-)",
+			`warning /components/schemas/A/patternProperties/^(a)\1$`,
+			"error /components/schemas/B/pattern",
+		}, got)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(`
+openapi: 3.1.0
+info: {title: API, version: '1'}
+paths:
+  /users:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Missing'
+      responses:
+        '200': {}
+`))
+	require.NoError(t, err)
+	err = doc.Validate()
+
+	var list oas.ValidationErrors
+	require.ErrorAs(t, err, &list)
+	require.Len(t, list, 2)
+	require.True(t, list.HasErrors())
+	require.Equal(t, &oas.ValidationError{
+		Pointer:  "/paths/~1users/get/responses/200/description",
+		Code:     oas.CodeRequired,
+		Severity: oas.SeverityError,
+		Message:  "campo obrigatório",
+	}, list[1])
+
+	// As alcança cada item; Is compara por código e ponteiro
+	var first *oas.ValidationError
+	require.ErrorAs(t, err, &first)
+	require.Equal(t, "/paths/~1users/get/parameters/0", first.Pointer)
+	require.Equal(t, oas.CodeInvalidRef, first.Code)
+	require.ErrorIs(t, err, &oas.ValidationError{Code: oas.CodeRequired})
+	require.ErrorIs(t, err, &oas.ValidationError{Code: oas.CodeInvalidRef, Pointer: "/paths/~1users/get/parameters/0"})
+	require.NotErrorIs(t, err, &oas.ValidationError{Code: oas.CodeRequired, Pointer: "/info"})
+	require.NotErrorIs(t, err, oas.ErrMissingVersion)
+	// causa original preservada
+	require.ErrorIs(t, err, oas.ErrRefNotFound)
+
+	// só avisos
+	require.False(t, oas.ValidationErrors{{Severity: oas.SeverityWarning}}.HasErrors())
+}
+
+func TestDecodeErrorPointer(t *testing.T) {
+	cases := []struct {
+		doc     string
+		pointer string
+		message string
+	}{
+		// união com forma inválida
+		{`{"openapi": "3.1.0", "components": {"schemas": {"User": {"properties": {"tags": {"type": 1}}}}}}`,
+			"/components/schemas/User/properties/tags/type", "valor deve ser string ou []string"},
+		// campo escalar com tipo errado
+		{`{"openapi": "3.1.0", "paths": {"/users": {"get": {"parameters": [{"name": "a", "in": "query"}, {"name": "b", "in": "query", "required": "sim"}]}}}}`,
+			"/paths/~1users/get/parameters/1/required", "cannot unmarshal string"},
+		// dentro de mapa de mapas
+		{`{"openapi": "3.1.0", "paths": {"/x": {"get": {"responses": {"200": {"description": "ok", "headers": {"X": {"required": 1}}}}}}}}`,
+			"/paths/~1x/get/responses/200/headers/X/required", "cannot unmarshal number"},
+	}
+	for _, c := range cases {
+		_, err := oas.Load(context.Background(), oas.FromBytes([]byte(c.doc), "openapi.json"))
+		var ve *oas.ValidationError
+		require.ErrorAs(t, err, &ve, c.doc)
+		require.Equal(t, c.pointer, ve.Pointer)
+		require.Equal(t, oas.CodeDecode, ve.Code)
+		require.Contains(t, ve.Message, c.message)
+
+		var le *oas.LoadError
+		require.ErrorAs(t, err, &le)
+	}
+
+	// YAML também
+	_, err := oas.LoadYAML([]byte("openapi: 3.1.0\ninfo: {title: [x]}\n"))
+	var ve *oas.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, "/info/title", ve.Pointer)
+
+	// sintaxe inválida não é localizada
+	_, err = oas.Load(context.Background(), oas.FromBytes([]byte(`{"openapi": "3.1.0",`)))
+	require.False(t, errors.As(err, &ve))
+}
+
+func TestDocument_ValidatePathTemplates(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(`
+openapi: 3.1.0
+info: {title: API, version: '1'}
+paths:
+  /users/{id}:
+    parameters:
+      - $ref: '#/components/parameters/ID'
+    get:
+      responses: {'200': {description: ok}}
+    put:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+        - {name: extra, in: path, required: true, schema: {type: string}}
+      responses: {'200': {description: ok}}
+  /users/{userId}:
+    get:
+      parameters:
+        - {name: X-Trace, in: header, schema: {type: string}}
+        - {name: x-trace, in: header, schema: {type: string}}
+        - {name: userId, in: query, schema: {type: string}}
+      responses: {'200': {description: ok}}
+  /posts/{postId}/comments/{postId}:
+    $ref: '#/components/pathItems/Comments'
+  /broken/{id:
+    get:
+      responses: {'200': {description: ok}}
+components:
+  parameters:
+    ID: {name: id, in: path, required: true, schema: {type: string}}
+  pathItems:
+    Comments:
+      get:
+        parameters:
+          - {name: commentId, in: path, required: true, schema: {type: string}}
+        responses: {'200': {description: ok}}
+`))
+	require.NoError(t, err)
+
+	var list oas.ValidationErrors
+	require.ErrorAs(t, doc.Validate(), &list)
+	got := make([]string, len(list))
+	for i, e := range list {
+		got[i] = string(e.Code) + " " + e.Error()
+	}
+	require.ElementsMatch(t, []string{
+		// variável sem parâmetro (o $ref de components conta como declarado)
+		"undeclared /paths/~1users~1{userId}/get: parâmetro de path {userId} não declarado",
+		// parâmetro sem variável
+		`unused /paths/~1users~1{id}/put/parameters/1: parâmetro de path "extra" não aparece no template`,
+		// headers não diferenciam maiúsculas
+		`duplicate /paths/~1users~1{userId}/get/parameters/1: parâmetro "x-trace" em header repetido`,
+		"conflict /paths/~1users~1{userId}: template colide com /users/{id}",
+		"duplicate /paths/~1posts~1{postId}~1comments~1{postId}: variável {postId} repetida no template",
+		// path item via $ref: erros apontam para o próprio path
+		"undeclared /paths/~1posts~1{postId}~1comments~1{postId}: parâmetro de path {postId} não declarado",
+		`unused /paths/~1posts~1{postId}~1comments~1{postId}: parâmetro de path "commentId" não aparece no template`,
+		"invalid-name /paths/~1broken~1{id: template malformado",
+	}, got)
+}
+
+func TestDocument_ValidateOperations(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(`
+openapi: 3.1.0
+info: {title: API, version: '1'}
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        '200':
+          description: ok
+          links:
+            byId: {operationId: getUser}
+            byRef: {operationRef: '#/paths/~1users~1%7Bid%7D/get'}
+            viaComponent: {operationRef: '#/components/pathItems/Orders/get'}
+            missing: {operationId: deleteUser}
+            missingRef: {operationRef: '#/paths/~1users/delete'}
+            external: {operationRef: 'other.yaml#/paths/~1x/get'}
+            shared: {$ref: '#/components/links/Shared'}
+    post:
+      operationId: listUsers
+      callbacks:
+        onEvent:
+          '{$request.body#/url}':
+            post:
+              operationId: getUser
+              responses: {'200': {description: ok}}
+      responses: {'201': {$ref: '#/components/responses/Created'}}
+  /users/{id}:
+    get:
+      operationId: getUser
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses: {'200': {description: ok}}
+  /orders:
+    $ref: '#/components/pathItems/Orders'
+webhooks:
+  newUser:
+    post:
+      operationId: listUsers
+      responses: {'200': {description: ok}}
+components:
+  pathItems:
+    Orders:
+      get:
+        operationId: listOrders
+        responses: {'200': {description: ok}}
+  links:
+    Shared: {operationId: nope}
+  responses:
+    Created:
+      description: criado
+      links:
+        self: {operationRef: '#/paths/~1orders/get'}
+        other: {operationId: missingToo}
+`))
+	require.NoError(t, err)
+
+	var list oas.ValidationErrors
+	require.ErrorAs(t, doc.Validate(), &list)
+	got := make([]string, len(list))
+	for i, e := range list {
+		got[i] = string(e.Code) + " " + e.Error()
+	}
+	require.ElementsMatch(t, []string{
+		// duplicados em paths, callbacks e webhooks
+		`duplicate /paths/~1users/post/operationId: operationId "listUsers" repetido (já usado em /paths/~1users/get)`,
+		`duplicate /paths/~1users~1{id}/get/operationId: operationId "getUser" repetido (já usado em /paths/~1users/post/callbacks/onEvent/{$request.body#~1url}/post)`,
+		`duplicate /webhooks/newUser/post/operationId: operationId "listUsers" repetido (já usado em /paths/~1users/get)`,
+		// links sem operação de destino
+		`undeclared /paths/~1users/get/responses/200/links/missing/operationId: operationId "deleteUser" não existe`,
+		`undeclared /paths/~1users/get/responses/200/links/missingRef/operationRef: operationRef "#/paths/~1users/delete" não aponta para uma operação`,
+		`undeclared /components/responses/Created/links/other/operationId: operationId "missingToo" não existe`,
+		`undeclared /components/links/Shared/operationId: operationId "nope" não existe`,
+	}, got)
+
+	// operationRef externo é conferido com RefLoader
+	loader := oas.WithRefLoader(oas.MapLoader{"other.yaml": []byte(`paths: {/x: {post: {responses: {}}}}`)})
+	require.ErrorIs(t, doc.Validate(loader), &oas.ValidationError{
+		Code:    oas.CodeUndeclared,
+		Pointer: "/paths/~1users/get/responses/200/links/external/operationRef",
+	})
+}
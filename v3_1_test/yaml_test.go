@@ -0,0 +1,232 @@
+package oas_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	oas "github.com/leandroluk/go-oas/v3_1"
+)
+
+const petstoreYAML = `
+openapi: 3.1.0
+info:
+  title: Petstore
+  version: 1.0.0
+x-defaults: &defaults
+  description: padrão
+paths:
+  /pets/{id}:
+    $ref: '#/components/pathItems/Pet'
+  /pets:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+      responses:
+        200:
+          <<: *defaults
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      type: [object, "null"]
+      required: [id]
+      properties:
+        id: {type: integer, minimum: 1}
+        tags:
+          type: array
+          prefixItems:
+            - type: string
+        meta:
+          additionalProperties: false
+        extra:
+          additionalProperties:
+            type: string
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema: {type: integer}
+  pathItems:
+    Pet:
+      get:
+        responses:
+          default:
+            description: ok
+`
+
+func TestLoadYAML(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(petstoreYAML))
+	require.NoError(t, err)
+	require.Equal(t, "3.1.0", doc.OpenAPI)
+	require.Equal(t, "Petstore", doc.Info.Title)
+
+	// ref em PathItem
+	require.NotNil(t, doc.Paths["/pets/{id}"].Ref)
+
+	// chave inteira (200) vira string e merge key é aplicada
+	op := doc.Paths["/pets"].PathItem.Get
+	require.Equal(t, "padrão", op.Responses["200"].Resp.Description)
+	require.NotNil(t, op.Parameters[0].Ref)
+
+	schema := op.Responses["200"].Resp.Content["application/json"].Schema.Schema
+	require.Equal(t, "#/components/schemas/Pet", schema.Items.Single.Ref.Ref)
+
+	// StringOrArray, AdditionalProperties bool/schema, PrefixItems
+	pet := doc.Components.Schemas["Pet"].Schema
+	require.Equal(t, []string{"object", "null"}, pet.Type.Many)
+	require.False(t, *pet.Properties["meta"].Schema.AdditionalProperties.Allows)
+	require.NotNil(t, pet.Properties["extra"].Schema.AdditionalProperties.Schema.Schema)
+	require.Len(t, pet.Properties["tags"].Schema.PrefixItems, 1)
+	require.Equal(t, 1.0, *pet.Properties["id"].Schema.Minimum)
+}
+
+func TestLoadYAML_Errors(t *testing.T) {
+	// yaml inválido
+	{
+		_, err := oas.LoadYAML([]byte("openapi: [3.1.0"))
+		require.Error(t, err)
+	}
+	// union inválido
+	{
+		_, err := oas.LoadYAML([]byte("openapi: 3.1.0\ncomponents:\n  schemas:\n    A:\n      type: 123\n"))
+		require.Error(t, err)
+	}
+	// chave não escalar
+	{
+		_, err := oas.LoadYAML([]byte("? [a, b]\n: c\n"))
+		require.Error(t, err)
+	}
+	// valor não representável em JSON
+	{
+		_, err := oas.LoadYAML([]byte("openapi: 3.1.0\nx-inf: .inf\n"))
+		require.Error(t, err)
+	}
+}
+
+func TestLoadYAML_Aliases(t *testing.T) {
+	// aliases e merge keys válidos são expandidos
+	doc, err := oas.LoadYAML([]byte(`
+openapi: 3.1.0
+info: &info {title: API, version: 1.0.0}
+x-info: *info
+x-merged: {<<: *info, title: Outra}
+`), oas.WithSourceMap())
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"title": "API", "version": "1.0.0"}, doc.Extensions["x-info"])
+	require.Equal(t, "Outra", doc.Extensions["x-merged"].(map[string]any)["title"])
+
+	// alias cíclico: erro em vez de estouro de pilha
+	for _, src := range []string{
+		"openapi: 3.1.0\nx-a: &x {b: *x}\n",
+		"openapi: 3.1.0\nx-a: &x [*x]\n",
+		"openapi: 3.1.0\nx-a: &x {<<: *x}\n",
+	} {
+		_, err := oas.LoadYAML([]byte(src))
+		require.ErrorContains(t, err, "cíclico", src)
+		_, err = oas.LoadYAML([]byte(src), oas.WithSourceMap())
+		require.ErrorContains(t, err, "cíclico", src)
+	}
+
+	// "billion laughs": falha rápido em vez de expandir 10^9 nós
+	var laughs strings.Builder
+	laughs.WriteString("openapi: 3.1.0\nx-0: &a0 [lol, lol, lol, lol, lol, lol, lol, lol, lol, lol]\n")
+	for i := 1; i <= 9; i++ {
+		laughs.WriteString(fmt.Sprintf("x-%d: &a%d [", i, i))
+		for j := range 10 {
+			if j > 0 {
+				laughs.WriteString(", ")
+			}
+			laughs.WriteString(fmt.Sprintf("*a%d", i-1))
+		}
+		laughs.WriteString("]\n")
+	}
+	_, err = oas.LoadYAML([]byte(laughs.String()))
+	require.ErrorContains(t, err, "aliases em excesso")
+}
+
+func TestDocument_YAML_RoundTrip(t *testing.T) {
+	doc, err := oas.LoadYAML([]byte(petstoreYAML))
+	require.NoError(t, err)
+
+	out, err := doc.YAML()
+	require.NoError(t, err)
+	s := string(out)
+
+	// ordem das chaves segue a ordem dos campos do Document
+	require.Less(t, strings.Index(s, "openapi:"), strings.Index(s, "info:"))
+	require.Less(t, strings.Index(s, "info:"), strings.Index(s, "paths:"))
+	require.Less(t, strings.Index(s, "paths:"), strings.Index(s, "components:"))
+	// códigos de status permanecem strings
+	require.Contains(t, s, `"200":`)
+
+	// saída estável
+	again, err := doc.YAML()
+	require.NoError(t, err)
+	require.Equal(t, s, string(again))
+
+	// YAML -> Document -> YAML preserva o mesmo JSON
+	back, err := oas.LoadYAML(out)
+	require.NoError(t, err)
+	a, _ := json.Marshal(doc)
+	b, _ := json.Marshal(back)
+	require.JSONEq(t, string(a), string(b))
+}
+
+func TestDocument_YAML_MultilineStrings(t *testing.T) {
+	descriptions := []string{
+		"linha 1\nlinha 2\n",
+		"\tx\n",
+		"  recuado\nfim",
+		"a\n\tb",
+		"espaço no fim \nb",
+		"crlf\r\nb",
+	}
+	for _, d := range descriptions {
+		doc := oas.NewBuilder().SetTitle("API").SetVersion("1").SetDescription(d).Build()
+		doc.Info.Description = &d // SetDescription apara os espaços
+		out, err := doc.YAML()
+		require.NoError(t, err)
+		back, err := oas.LoadYAML(out)
+		require.NoError(t, err, "%q", d)
+		require.Equal(t, d, *back.Info.Description)
+	}
+
+	// texto comum continua em bloco literal
+	d := "linha 1\nlinha 2"
+	doc := oas.NewBuilder().SetTitle("API").SetVersion("1").Build()
+	doc.Info.Description = &d
+	out, err := doc.YAML()
+	require.NoError(t, err)
+	require.Contains(t, string(out), "description: |-\n")
+}
+
+func TestBuilder_YAML(t *testing.T) {
+	b := oas.NewBuilder().
+		SetTitle("API").
+		SetVersion("1.0.0").
+		SetDescription("linha 1\nlinha 2")
+	b.Path("/items").
+		Get("Lista itens").
+		ResponseJSON(200, "ok", oas.SchemaOrRef{Schema: &oas.Schema{Type: oas.TypeString, MultipleOf: oas.Ptr(0.5)}}).
+		DoneOp().
+		DonePath()
+
+	out, err := b.YAML()
+	require.NoError(t, err)
+	require.Contains(t, string(out), "title: API")
+	require.Contains(t, string(out), "multipleOf: 0.5")
+
+	doc, err := oas.LoadYAML(out)
+	require.NoError(t, err)
+	require.Equal(t, "linha 1\nlinha 2", *doc.Info.Description)
+	require.Contains(t, doc.Paths, "/items")
+}